@@ -0,0 +1,224 @@
+//go:build !headless
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/capture"
+	"github.com/PeterShin23/MyAssistant/backend/internal/conversation"
+	"github.com/PeterShin23/MyAssistant/backend/internal/key"
+	"github.com/PeterShin23/MyAssistant/backend/internal/openai"
+	"github.com/PeterShin23/MyAssistant/backend/internal/queue"
+	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
+	"github.com/PeterShin23/MyAssistant/backend/internal/tts"
+
+	"github.com/spf13/cobra"
+)
+
+// newListenCmd builds the listen command. It is the only command that needs
+// the key hook and audio recorder, so headless builds leave it out.
+func newListenCmd() *cobra.Command {
+	var noAudio bool
+	var noScreen bool
+	var pretty bool
+	var wsURL string
+	var wsToken string
+	var silent bool
+	var providerName string
+	var resumeID string
+	var profileName string
+	var speak bool
+	var followUp bool
+
+	var listenCmd = &cobra.Command{
+		Use:   "listen",
+		Short: "Start listening for hotkey press",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("⌨️ Waiting for configured key hold")
+			fmt.Println("👋 Let's get to work!")
+
+			// Check for environment variable fallbacks
+			if wsURL == "" {
+				wsURL = os.Getenv("MYASSISTANT_WS_URL")
+			}
+			if wsToken == "" {
+				wsToken = os.Getenv("MYASSISTANT_WS_TOKEN")
+			}
+
+			// Validate silent mode requires WebSocket
+			if silent && wsURL == "" {
+				fmt.Println("❌ Error: --silent mode requires --ws-url to be set")
+				fmt.Println("   Silent mode disables terminal output, so WebSocket is required for receiving responses")
+				os.Exit(1)
+			}
+
+			// With neither a screenshot nor audio the hotkey has nothing to capture
+			if noAudio && noScreen {
+				fmt.Println("❌ Error: --no-audio and --no-screen can't be used together")
+				os.Exit(1)
+			}
+
+			// Create StreamWriter instances
+			var writer stream.StreamWriter
+			var wsWriter *stream.WSWriter
+
+			// If WebSocket URL is provided, create a WSWriter
+			if wsURL != "" {
+				wsWriter = stream.NewWSWriter(wsURL, wsToken)
+
+				if silent {
+					// Silent mode: only use WebSocket, no stdout
+					writer = wsWriter
+					fmt.Println("🤫 Silent mode enabled - output only to WebSocket")
+				} else {
+					// Normal mode: use both stdout and WebSocket
+					stdoutWriter := stream.NewStdoutWriter(pretty)
+					writer = stream.NewTeeWriter(stdoutWriter, wsWriter)
+				}
+			} else {
+				// No WebSocket: only stdout
+				stdoutWriter := stream.NewStdoutWriter(pretty)
+				writer = stdoutWriter
+			}
+
+			rules := loadRules(profileName)
+
+			// Speak answers for when you're away from the keyboard
+			if speak || rules.TTS.Enabled {
+				speaker, err := tts.New(rules.TTS, providerConfig(rules))
+				if err != nil {
+					fmt.Println("Failed to set up text-to-speech:", err)
+					os.Exit(1)
+				}
+				writer = stream.NewTeeWriter(writer, stream.NewTTSWriter(speaker))
+				fmt.Printf("🔊 Speaking answers with %s\n", speaker.Name())
+			}
+
+			session := newSession(rules, writer, providerName)
+
+			// Persist turns so the conversation can be resumed later
+			if resumeID != "" {
+				log, err := conversation.Open(resumeID)
+				if err != nil {
+					fmt.Println("Failed to open session:", err)
+					os.Exit(1)
+				}
+				if err := session.Resume(context.Background(), log); err != nil {
+					fmt.Println("Failed to resume session:", err)
+					os.Exit(1)
+				}
+			} else {
+				log, err := conversation.New()
+				if err != nil {
+					fmt.Println("Failed to create session log:", err)
+					os.Exit(1)
+				}
+				session.AttachLog(log)
+				fmt.Printf("💾 Session %s (resume with --resume %s)\n", log.ID, log.ID)
+			}
+
+			// Create capture manager for remote screenshot triggers
+			captureManager := capture.NewManager(session)
+
+			// If WebSocket is enabled, set up command handler for remote screenshot triggers
+			if wsWriter != nil {
+				wsWriter.SetCommandHandler(func(cmd stream.Command) {
+					switch cmd.Name {
+					case "screenshot":
+						fmt.Println("📱 Remote screenshot command received")
+						if err := captureManager.TriggerScreenshot(); err != nil {
+							fmt.Printf("❌ Remote screenshot failed: %v\n", err)
+						}
+					case "cancel":
+						fmt.Println("📱 Remote cancel command received")
+						if !session.Cancel() {
+							fmt.Println("Nothing to cancel")
+						}
+					case "profile":
+						fmt.Printf("📱 Remote profile command received: %s\n", cmd.Value)
+						if err := session.SetProfile(cmd.Value); err != nil {
+							fmt.Printf("❌ Profile switch failed: %v\n", err)
+						}
+					case "cycle-profile":
+						fmt.Println("📱 Remote cycle-profile command received")
+						if _, err := session.CycleProfile(); err != nil {
+							fmt.Printf("❌ Profile switch failed: %v\n", err)
+						}
+					case "followup":
+						fmt.Printf("📱 Remote follow-up received: %s\n", cmd.Value)
+						sendFollowUp(session, cmd.Value, pretty)
+					}
+				})
+			}
+
+			// Keep captures the provider couldn't answer and deliver them late
+			if rules.Queue.IsEnabled() {
+				session.SetQueueing(true)
+				if items, err := queue.List(); err == nil && len(items) > 0 {
+					fmt.Printf("📥 %d queued capture(s) will be retried in the background\n", len(items))
+				}
+				interval := time.Duration(rules.Queue.RetrySec) * time.Second
+				go session.RunQueue(context.Background(), interval, rules.Queue.MaxAttempts, pretty)
+			}
+
+			// Typed lines continue the conversation without a new capture
+			if followUp {
+				fmt.Println("💬 Follow-up mode: type a question and press Enter to ask about the last answer")
+				go readFollowUps(session, pretty)
+			}
+
+			if err := key.StartKeyListener(session, noAudio, noScreen, pretty, wsURL, wsToken); err != nil {
+				fmt.Println("Key Listener failed:", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	listenCmd.Flags().BoolVar(&noAudio, "no-audio", false, "Disable audio recording")
+	listenCmd.Flags().BoolVar(&noScreen, "no-screen", false, "Disable screen capture (voice-only questions)")
+	listenCmd.Flags().BoolVar(&pretty, "pretty", false, "Outputs pretty markdown instead of streamed data")
+	listenCmd.Flags().StringVar(&wsURL, "ws-url", "", "WebSocket URL for streaming output")
+	listenCmd.Flags().StringVar(&wsToken, "ws-token", "", "Authorization token for WebSocket connection")
+	listenCmd.Flags().BoolVar(&silent, "silent", false, "Disable terminal output (requires --ws-url)")
+	listenCmd.Flags().BoolVar(&followUp, "followup", false, "Read follow-up questions from stdin")
+	listenCmd.Flags().BoolVar(&speak, "speak", false, "Speak answers aloud (see \"tts\" in rules.json)")
+	listenCmd.Flags().StringVar(&providerName, "provider", "", "LLM provider to use (overrides rules.json \"provider\")")
+	listenCmd.Flags().StringVar(&profileName, "profile", "", "Prompt profile to start with (see \"profiles\" in rules.json)")
+	listenCmd.Flags().StringVar(&resumeID, "resume", "", "Resume a stored session by ID (see `sessions list`)")
+
+	return listenCmd
+}
+
+// readFollowUps sends every non-empty stdin line as a follow-up question
+func readFollowUps(session *openai.Session, pretty bool) {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		sendFollowUp(session, scanner.Text(), pretty)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Println("❌ Failed to read follow-ups:", err)
+	}
+}
+
+// sendFollowUp asks a typed question in the current conversation
+func sendFollowUp(session *openai.Session, text string, pretty bool) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if err := session.FollowUp(context.Background(), text, pretty); err != nil {
+		if errors.Is(err, openai.ErrCancelled) {
+			fmt.Println("⏹️  Request cancelled")
+		} else if errors.Is(err, openai.ErrQueued) {
+			fmt.Println("📥 Provider unreachable,", err)
+		} else {
+			fmt.Println("❌ Error during follow-up:", err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build headless
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// newListenCmd stands in for listen in headless builds, which leave out the
+// key hook and audio recorder so the other commands run without X11 or PortAudio
+func newListenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "listen",
+		Short: "Start listening for hotkey press (not available in headless builds)",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("❌ Error: this build has no hotkey support, rebuild without -tags headless to use listen")
+			os.Exit(1)
+		},
+	}
+}
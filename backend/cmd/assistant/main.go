@@ -1,14 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
-	"github.com/PeterShin23/MyAssistant/backend/internal/capture"
-	"github.com/PeterShin23/MyAssistant/backend/internal/key"
+	"github.com/PeterShin23/MyAssistant/backend/internal/cache"
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/conversation"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/ocr"
 	"github.com/PeterShin23/MyAssistant/backend/internal/openai"
+	"github.com/PeterShin23/MyAssistant/backend/internal/queue"
+	"github.com/PeterShin23/MyAssistant/backend/internal/redact"
+	"github.com/PeterShin23/MyAssistant/backend/internal/screen"
 	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
+	"github.com/PeterShin23/MyAssistant/backend/internal/tools"
+	"github.com/PeterShin23/MyAssistant/backend/internal/transcribe"
+	"github.com/PeterShin23/MyAssistant/backend/internal/usage"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
@@ -16,123 +32,304 @@ import (
 
 // To Run: go run ./cmd/assistant
 func main() {
+	// .env is optional: a self-hosted endpoint in rules.json may need no API key
 	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found. Exiting")
-		os.Exit(1)
-	}
-
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		fmt.Println("No OPENAI_API_KEY configured. Exiting")
-		os.Exit(1)
+		fmt.Println("No .env file found, using environment and rules.json")
 	}
 
-	fmt.Println("⌨️ Waiting for configured key hold")
-
 	var rootCmd = &cobra.Command{
 		Use:   "assistant",
 		Short: "MyAssistant CLI - your personal screen/audio capture tool",
 	}
 
-	var noAudio bool
 	var pretty bool
 	var wsURL string
 	var wsToken string
-	var silent bool
+	var providerName string
+	var profileName string
+
+	var askImages []string
+	var askAudio string
+	var askText string
 
-	var listenCmd = &cobra.Command{
-		Use:   "listen",
-		Short: "Start listening for hotkey press",
+	var askCmd = &cobra.Command{
+		Use:   "ask",
+		Short: "Ask once about image, audio or text files and print the answer",
+		Long: `Runs a single request through the same pipeline as listen, without the
+key hook or a screen. Text is read from stdin when --text is "-", or when
+nothing else is given and stdin is not a terminal.`,
+		Example: `  assistant ask --image .data/screenshot.png --text "What's wrong here?"
+  git diff | assistant ask --profile review`,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("👋 Let's get to work!")
+			text := askText
+			if text == "-" || (text == "" && len(askImages) == 0 && askAudio == "" && stdinIsPipe()) {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					fmt.Println("Failed to read stdin:", err)
+					os.Exit(1)
+				}
+				text = string(data)
+			}
+			text = strings.TrimSpace(text)
+			if len(askImages) == 0 && askAudio == "" && text == "" {
+				fmt.Println("❌ Error: nothing to ask, pass --image, --audio or --text, or pipe text on stdin")
+				os.Exit(1)
+			}
+
+			input := openai.Input{AudioPath: askAudio, Text: text}
+			for i, path := range askImages {
+				shot := screen.Screenshot{Path: path}
+				if len(askImages) > 1 {
+					shot.Label = fmt.Sprintf("Image %d", i+1)
+				}
+				input.Screenshots = append(input.Screenshots, shot)
+			}
+			for _, path := range append(append([]string(nil), askImages...), askAudio) {
+				if path == "" {
+					continue
+				}
+				if _, err := os.Stat(path); err != nil {
+					fmt.Println("❌ Error:", err)
+					os.Exit(1)
+				}
+			}
 
-			// Check for environment variable fallbacks
 			if wsURL == "" {
 				wsURL = os.Getenv("MYASSISTANT_WS_URL")
 			}
 			if wsToken == "" {
 				wsToken = os.Getenv("MYASSISTANT_WS_TOKEN")
 			}
-
-			// Validate silent mode requires WebSocket
-			if silent && wsURL == "" {
-				fmt.Println("❌ Error: --silent mode requires --ws-url to be set")
-				fmt.Println("   Silent mode disables terminal output, so WebSocket is required for receiving responses")
-				os.Exit(1)
+			var writer stream.StreamWriter = stream.NewStdoutWriter(pretty)
+			if wsURL != "" {
+				writer = stream.NewTeeWriter(writer, stream.NewWSWriter(wsURL, wsToken))
 			}
 
-			// Create StreamWriter instances
-			var writer stream.StreamWriter
-			var wsWriter *stream.WSWriter
+			rules := loadRules(profileName)
+			session := newSession(rules, writer, providerName)
 
-			// If WebSocket URL is provided, create a WSWriter
-			if wsURL != "" {
-				wsWriter = stream.NewWSWriter(wsURL, wsToken)
+			// Ctrl-C cancels the request like Escape does in listen
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
 
-				if silent {
-					// Silent mode: only use WebSocket, no stdout
-					writer = wsWriter
-					fmt.Println("🤫 Silent mode enabled - output only to WebSocket")
+			err := session.Process(ctx, input, pretty)
+			writer.Close()
+			if err != nil {
+				if errors.Is(err, openai.ErrCancelled) {
+					fmt.Println("⏹️  Request cancelled")
 				} else {
-					// Normal mode: use both stdout and WebSocket
-					stdoutWriter := stream.NewStdoutWriter(pretty)
-					writer = stream.NewTeeWriter(stdoutWriter, wsWriter)
+					fmt.Println("❌ Error during OpenAI processing:", err)
 				}
-			} else {
-				// No WebSocket: only stdout
-				stdoutWriter := stream.NewStdoutWriter(pretty)
-				writer = stdoutWriter
+				os.Exit(1)
+			}
+		},
+	}
+
+	askCmd.Flags().StringArrayVar(&askImages, "image", nil, "Image to send (repeat for several)")
+	askCmd.Flags().StringVar(&askAudio, "audio", "", "Recorded question to transcribe (WAV or MP3)")
+	askCmd.Flags().StringVar(&askText, "text", "", "Question to send, or \"-\" to read it from stdin")
+	askCmd.Flags().StringVar(&profileName, "profile", "", "Prompt profile to use (see \"profiles\" in rules.json)")
+	askCmd.Flags().StringVar(&providerName, "provider", "", "LLM provider to use (overrides rules.json \"provider\")")
+	askCmd.Flags().BoolVar(&pretty, "pretty", false, "Outputs pretty markdown instead of streamed data")
+	askCmd.Flags().StringVar(&wsURL, "ws-url", "", "WebSocket URL to also stream the answer to")
+	askCmd.Flags().StringVar(&wsToken, "ws-token", "", "Authorization token for WebSocket connection")
+
+	var clearCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Delete all files in the .data folder",
+		Run: func(cmd *cobra.Command, args []string) {
+			err := clearDataFolder(".data")
+			if err != nil {
+				fmt.Println("Failed to clear .data:", err)
+				os.Exit(1)
+			}
+			fmt.Println("🧹 Cleared all files in .data/")
+		},
+	}
+
+	var sessionsCmd = &cobra.Command{
+		Use:   "sessions",
+		Short: "Manage stored conversations",
+	}
+
+	var sessionsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List stored conversations, most recent first",
+		Run: func(cmd *cobra.Command, args []string) {
+			infos, err := conversation.List()
+			if err != nil {
+				fmt.Println("Failed to list sessions:", err)
+				os.Exit(1)
 			}
+			if len(infos) == 0 {
+				fmt.Println("No stored sessions")
+				return
+			}
+			for _, info := range infos {
+				fmt.Printf("%s  %s  %3d turns  %s\n",
+					info.ID, info.Updated.Format("2006-01-02 15:04"), info.Turns, preview(info.Preview, 60))
+			}
+		},
+	}
+
+	sessionsCmd.AddCommand(sessionsListCmd)
 
-			session, err := openai.NewSession(writer)
+	var queueCmd = &cobra.Command{
+		Use:   "queue",
+		Short: "Manage captures that failed to send",
+	}
+
+	var queueListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List queued captures, oldest first",
+		Run: func(cmd *cobra.Command, args []string) {
+			items, err := queue.List()
 			if err != nil {
-				fmt.Println("Failed to create OpenAI session:", err)
+				fmt.Println("Failed to list queue:", err)
 				os.Exit(1)
 			}
+			if len(items) == 0 {
+				fmt.Println("No queued captures")
+				return
+			}
+			for _, item := range items {
+				fmt.Printf("%s  %s  %2d attempts  %s\n",
+					item.ID, item.Created.Format("2006-01-02 15:04"), item.Attempts, preview(item.Preview(), 60))
+				if item.LastError != "" {
+					fmt.Printf("    last error: %s\n", preview(item.LastError, 100))
+				}
+			}
+		},
+	}
 
-			// Create capture manager for remote screenshot triggers
-			captureManager := capture.NewManager(session)
+	var queueRetryCmd = &cobra.Command{
+		Use:   "retry [id]",
+		Short: "Send queued captures now, or just the given one",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var items []queue.Item
+			if len(args) == 1 {
+				item, err := queue.Get(args[0])
+				if err != nil {
+					fmt.Println("Failed to load capture:", err)
+					os.Exit(1)
+				}
+				items = append(items, *item)
+			} else {
+				var err error
+				if items, err = queue.List(); err != nil {
+					fmt.Println("Failed to list queue:", err)
+					os.Exit(1)
+				}
+			}
+			if len(items) == 0 {
+				fmt.Println("No queued captures")
+				return
+			}
 
-			// If WebSocket is enabled, set up command handler for remote screenshot triggers
-			if wsWriter != nil {
-				wsWriter.SetCommandHandler(func(command string) {
-					if command == "screenshot" {
-						fmt.Println("📱 Remote screenshot command received")
-						if err := captureManager.TriggerScreenshot(); err != nil {
-							fmt.Printf("❌ Remote screenshot failed: %v\n", err)
-						}
+			rules := loadRules(profileName)
+			session := newSession(rules, stream.NewStdoutWriter(pretty), providerName)
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			failed := 0
+			for _, item := range items {
+				fmt.Printf("📤 Retrying queued capture %s\n", item.ID)
+				if err := session.RetryItem(ctx, item, pretty); err != nil {
+					if errors.Is(err, openai.ErrCancelled) {
+						fmt.Println("⏹️  Request cancelled")
+						os.Exit(1)
 					}
-				})
+					fmt.Printf("❌ %s failed: %v\n", item.ID, err)
+					failed++
+				}
 			}
+			if failed > 0 {
+				fmt.Printf("%d of %d captures are still queued\n", failed, len(items))
+				os.Exit(1)
+			}
+		},
+	}
+	queueRetryCmd.Flags().BoolVar(&pretty, "pretty", false, "Outputs pretty markdown instead of streamed data")
+	queueRetryCmd.Flags().StringVar(&providerName, "provider", "", "LLM provider to use (overrides rules.json \"provider\")")
+
+	var dropAll bool
 
-			if err := key.StartKeyListener(session, noAudio, pretty, wsURL, wsToken); err != nil {
-				fmt.Println("Key Listener failed:", err)
+	var queueDropCmd = &cobra.Command{
+		Use:   "drop [id]",
+		Short: "Delete a queued capture, or all of them with --all",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var ids []string
+			switch {
+			case len(args) == 1 && !dropAll:
+				ids = args
+			case len(args) == 0 && dropAll:
+				items, err := queue.List()
+				if err != nil {
+					fmt.Println("Failed to list queue:", err)
+					os.Exit(1)
+				}
+				for _, item := range items {
+					ids = append(ids, item.ID)
+				}
+			default:
+				fmt.Println("❌ Error: pass a capture ID or --all")
 				os.Exit(1)
 			}
+
+			for _, id := range ids {
+				if err := queue.Drop(id); err != nil {
+					fmt.Println("Failed to drop capture:", err)
+					os.Exit(1)
+				}
+				fmt.Printf("🗑️  Dropped %s\n", id)
+			}
 		},
 	}
+	queueDropCmd.Flags().BoolVar(&dropAll, "all", false, "Drop every queued capture")
 
-	listenCmd.Flags().BoolVar(&noAudio, "no-audio", false, "Disable audio recording")
-	listenCmd.Flags().BoolVar(&pretty, "pretty", false, "Outputs pretty markdown instead of streamed data")
-	listenCmd.Flags().StringVar(&wsURL, "ws-url", "", "WebSocket URL for streaming output")
-	listenCmd.Flags().StringVar(&wsToken, "ws-token", "", "Authorization token for WebSocket connection")
-	listenCmd.Flags().BoolVar(&silent, "silent", false, "Disable terminal output (requires --ws-url)")
+	queueCmd.AddCommand(queueListCmd, queueRetryCmd, queueDropCmd)
 
-	var clearCmd = &cobra.Command{
-		Use:   "clear",
-		Short: "Delete all files in the .data folder",
+	var since string
+
+	var usageCmd = &cobra.Command{
+		Use:   "usage",
+		Short: "Show token and audio usage with estimated cost",
 		Run: func(cmd *cobra.Command, args []string) {
-			err := clearDataFolder(".data")
+			lookback, err := usage.ParseSince(since)
 			if err != nil {
-				fmt.Println("Failed to clear .data:", err)
+				fmt.Println("Invalid --since:", err)
 				os.Exit(1)
 			}
-			fmt.Println("🧹 Cleared all files in .data/")
+			rules, err := config.Load()
+			if err != nil {
+				fmt.Println("Failed to load rules:", err)
+				os.Exit(1)
+			}
+
+			from := time.Now().Add(-lookback)
+			records, err := usage.Read(from)
+			if err != nil {
+				fmt.Println("Failed to read usage:", err)
+				os.Exit(1)
+			}
+			if len(records) == 0 {
+				fmt.Printf("No usage since %s\n", from.Format("2006-01-02 15:04"))
+				return
+			}
+			printUsage(from, usage.Summarize(records, rules.Prices))
 		},
 	}
+	usageCmd.Flags().StringVar(&since, "since", "7d", "How far back to report, e.g. 7d, 36h or 90m")
 
-	rootCmd.AddCommand(listenCmd)
+	rootCmd.AddCommand(newListenCmd())
+	rootCmd.AddCommand(askCmd)
 	rootCmd.AddCommand(clearCmd)
+	rootCmd.AddCommand(sessionsCmd)
+	rootCmd.AddCommand(usageCmd)
+	rootCmd.AddCommand(queueCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println("Error:", err)
@@ -140,6 +337,220 @@ func main() {
 	}
 }
 
+// loadRules loads rules.json and applies the --profile flag
+func loadRules(profileName string) *config.Rules {
+	rules, err := config.Load()
+	if err != nil {
+		fmt.Println("Failed to load rules:", err)
+		os.Exit(1)
+	}
+
+	// --profile takes precedence over rules.json
+	if profileName != "" {
+		if _, err := rules.ForProfile(profileName); err != nil {
+			fmt.Println("Failed to select profile:", err)
+			os.Exit(1)
+		}
+		rules.Profile = profileName
+	}
+	return rules
+}
+
+// newSession creates the provider and a session with OCR, usage tracking,
+// transcription, the answer cache and tools set up from rules
+func newSession(rules *config.Rules, writer stream.StreamWriter, providerName string) *openai.Session {
+	// --provider takes precedence over rules.json
+	if providerName == "" {
+		providerName = rules.Provider
+	}
+	provider, err := llm.New(providerName, providerConfig(rules))
+	if err != nil {
+		fmt.Println("Failed to create LLM provider:", err)
+		os.Exit(1)
+	}
+	provider = llm.WithRetry(provider, retryPolicy(rules))
+	fmt.Printf("🧠 Using provider: %s\n", provider.Name())
+
+	session, err := openai.NewSession(writer, provider, rules)
+	if err != nil {
+		fmt.Println("Failed to create OpenAI session:", err)
+		os.Exit(1)
+	}
+	if len(rules.Profiles) > 0 {
+		active := session.Profile()
+		if active == "" {
+			active = "(none)"
+		}
+		fmt.Printf("🎭 Profile: %s (available: %s)\n", active, strings.Join(rules.ProfileNames(), ", "))
+	}
+
+	// Extract screen text, which stands in for the image on models without vision
+	ocrEngine, err := ocr.New(rules.OCR)
+	if err != nil {
+		fmt.Println("Failed to set up OCR:", err)
+		os.Exit(1)
+	}
+	session.SetOCR(ocrEngine)
+	if rules.OCR.Engine != config.OCREngineNone {
+		fmt.Printf("🔤 OCR: %s\n", ocrEngine.Name())
+	}
+	if !rules.Chat.SupportsVision() && rules.OCR.Engine == config.OCREngineNone {
+		fmt.Println("Warning: chat.vision is false and no OCR engine is configured, screenshots will not be sent")
+	}
+
+	// Strip secrets and personal data from everything sent to the provider
+	redactor, err := redact.New(rules.Redaction)
+	if err != nil {
+		fmt.Println("Failed to set up redaction:", err)
+		os.Exit(1)
+	}
+	session.SetRedactor(redactor)
+	if redactor != nil {
+		fmt.Printf("🙈 Redaction: %s (audit log %s)\n", redactor.Describe(), redact.AuditPath)
+	}
+
+	// Record token and audio usage for `assistant usage`
+	usageStore := usage.NewStore()
+	session.SetUsage(usageStore)
+
+	// Transcribe with the provider, a separate compatible server, or locally
+	transcriptionProvider := session.Provider()
+	if rules.Transcription.Backend == config.TranscriptionOpenAICompatible {
+		transcriptionProvider, err = llm.New("openai", transcriptionConfig(rules))
+		if err != nil {
+			fmt.Println("Failed to create transcription provider:", err)
+			os.Exit(1)
+		}
+		transcriptionProvider = usage.Meter(llm.WithRetry(transcriptionProvider, retryPolicy(rules)), usageStore, session.Profile)
+	}
+	transcriber, err := transcribe.New(rules.Transcription, transcriptionProvider)
+	if err != nil {
+		fmt.Println("Failed to set up transcription:", err)
+		os.Exit(1)
+	}
+	session.SetTranscriber(transcriber)
+	if rules.Transcription.Backend != config.TranscriptionOpenAI {
+		fmt.Printf("🎙️  Transcription: %s\n", transcriber.Name())
+	}
+
+	// Replay answers for unchanged screens and questions
+	if rules.Cache.Enabled {
+		session.SetCache(cache.New(rules.Cache))
+		fmt.Printf("♻️  Answer cache enabled (TTL %dm)\n", rules.Cache.TTLMinutes)
+	}
+
+	// Offer local tools to the model when enabled in rules.json
+	toolRegistry, err := tools.NewFromConfig(rules.Tools)
+	if err != nil {
+		fmt.Println("Failed to set up tools:", err)
+		os.Exit(1)
+	}
+	if toolRegistry != nil {
+		session.SetTools(toolRegistry)
+		var names []string
+		for _, def := range toolRegistry.Definitions() {
+			names = append(names, def.Name)
+		}
+		fmt.Printf("🛠️  Tools enabled: %s\n", strings.Join(names, ", "))
+	}
+	return session
+}
+
+// providerConfig builds the LLM provider settings from rules.json,
+// falling back to environment variables for anything left unset
+func providerConfig(rules *config.Rules) llm.Config {
+	cfg := llm.Config{
+		BaseURL:            rules.Endpoint.BaseURL,
+		APIKey:             rules.Endpoint.APIKey,
+		ChatModel:          rules.Endpoint.ChatModel,
+		TranscriptionModel: rules.Endpoint.TranscriptionModel,
+	}
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = os.Getenv("OPENAI_BASE_URL")
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if cfg.ChatModel == "" {
+		cfg.ChatModel = os.Getenv("MYASSISTANT_CHAT_MODEL")
+	}
+	if cfg.TranscriptionModel == "" {
+		cfg.TranscriptionModel = os.Getenv("MYASSISTANT_TRANSCRIPTION_MODEL")
+	}
+	return cfg
+}
+
+// transcriptionConfig builds the connection settings of the openai-compatible
+// transcription backend
+func transcriptionConfig(rules *config.Rules) llm.Config {
+	return llm.Config{
+		BaseURL:            rules.Transcription.BaseURL,
+		APIKey:             rules.Transcription.APIKey,
+		TranscriptionModel: rules.Transcription.Model,
+	}
+}
+
+// retryPolicy converts the rules.json retry settings into an llm.RetryPolicy
+func retryPolicy(rules *config.Rules) llm.RetryPolicy {
+	return llm.RetryPolicy{
+		MaxAttempts:   rules.Retry.MaxAttempts,
+		BaseDelay:     time.Duration(rules.Retry.BaseDelayMs) * time.Millisecond,
+		MaxDelay:      time.Duration(rules.Retry.MaxDelayMs) * time.Millisecond,
+		MaxRetryAfter: time.Duration(rules.Retry.MaxRetryAfterSec) * time.Second,
+	}
+}
+
+// printUsage prints per-day and per-model usage tables
+func printUsage(from time.Time, report usage.Report) {
+	fmt.Printf("📊 Usage since %s\n\n", from.Format("2006-01-02 15:04"))
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "DAY\tMODEL\tREQUESTS\tPROMPT\tCOMPLETION\tIMAGE\tAUDIO (s)\tCOST\t")
+	for _, row := range report.ByDay {
+		printUsageRow(tw, row.Day, row)
+	}
+	tw.Flush()
+
+	fmt.Println()
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TOTAL\tMODEL\tREQUESTS\tPROMPT\tCOMPLETION\tIMAGE\tAUDIO (s)\tCOST\t")
+	for _, row := range report.ByModel {
+		printUsageRow(tw, "", row)
+	}
+	printUsageRow(tw, "all", usage.Row{Totals: report.Total})
+	tw.Flush()
+
+	if report.Total.Unpriced > 0 {
+		fmt.Printf("\n%d requests used models without a price; add them under \"prices\" in rules.json\n", report.Total.Unpriced)
+	}
+}
+
+func printUsageRow(tw *tabwriter.Writer, label string, row usage.Row) {
+	cost := fmt.Sprintf("$%.4f", row.Cost)
+	if row.Unpriced == row.Requests {
+		cost = "-"
+	}
+	fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%.0f\t%s\t\n",
+		label, row.Model, row.Requests, row.PromptTokens, row.CompletionTokens, row.ImageTokens, row.AudioSeconds, cost)
+}
+
+// stdinIsPipe reports whether stdin is redirected rather than a terminal
+func stdinIsPipe() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// preview flattens text onto one line and truncates it to max runes
+func preview(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return text
+}
+
 func clearDataFolder(folder string) error {
 	entries, err := os.ReadDir(folder)
 	if err != nil {
@@ -58,6 +58,7 @@ func StartRecording() error {
 		for recording {
 			if err := stream.Read(); err == nil {
 				buffer = append(buffer, tmpBuf...)
+				cutSegment()
 			}
 		}
 
@@ -81,6 +82,10 @@ func StopRecording() (string, error) {
 
 	recording = false
 
+	// Hand off the last segment of a segmented recording first, it's the
+	// only audio still waiting to be transcribed
+	segmented := flushSegments()
+
 	now := time.Now().Format("2006-01-02T15:04:05.000")
 	outputPath := fmt.Sprintf(".data/%s.wav", now)
 
@@ -101,6 +106,12 @@ func StopRecording() (string, error) {
 		return "", fmt.Errorf("Failed to write wav data: %w", err)
 	}
 
+	// A segmented recording is already transcribed and the WAV is only
+	// uploaded as a fallback, so skip the ffmpeg pass
+	if segmented {
+		return outputPath, nil
+	}
+
 	mp3Path, err := convertWavToMp3(outputPath)
 	if err != nil {
 		return "", fmt.Errorf("mp3 conversion failed: %w", err)
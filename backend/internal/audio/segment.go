@@ -0,0 +1,161 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const sampleRate = 44100
+
+// Segment is a piece of the current recording written as its own WAV file
+type Segment struct {
+	// Index counts segments from 0 in recording order
+	Index int
+	Path  string
+	// Final is set on the segment cut by StopRecording
+	Final bool
+}
+
+// SegmentHandler receives each segment of a segmented recording, in order
+type SegmentHandler func(seg Segment)
+
+type segmentJob struct {
+	Segment
+	samples []int16
+}
+
+var (
+	segMu      sync.Mutex
+	segSamples int // target segment length
+	segStart   int // start of the next segment in buffer
+	segIndex   int // index of the next segment
+	segJobs    chan segmentJob
+	segDone    chan struct{}
+)
+
+// minFinalSamples drops a last segment too short to hold any speech
+const minFinalSamples = sampleRate / 10
+
+// quietWindow is how far back from a segment's target end the cut may move
+// to land in a pause rather than mid-word
+const quietWindow = sampleRate / 2
+
+// StartSegmentedRecording records like StartRecording and also cuts the audio
+// into segments of about every, handing each to onSegment as soon as it is
+// written. StopRecording cuts the last segment and returns once onSegment has
+// seen it.
+func StartSegmentedRecording(every time.Duration, onSegment SegmentHandler) error {
+	segMu.Lock()
+	if segJobs != nil {
+		segMu.Unlock()
+		return nil // already recording
+	}
+	segSamples = max(int(every.Seconds()*sampleRate), 2*quietWindow)
+	segStart, segIndex = 0, 0
+	base := fmt.Sprintf(".data/%s-seg", time.Now().Format("2006-01-02T15:04:05.000"))
+	jobs := make(chan segmentJob, 8)
+	done := make(chan struct{})
+	segJobs, segDone = jobs, done
+	segMu.Unlock()
+
+	go writeSegments(base, jobs, done, onSegment)
+
+	if err := StartRecording(); err != nil {
+		segMu.Lock()
+		close(jobs)
+		segJobs, segDone = nil, nil
+		segMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// cutSegment hands off the next full segment, if the buffer holds one.
+// It runs on the recording goroutine after every read.
+func cutSegment() {
+	segMu.Lock()
+	defer segMu.Unlock()
+
+	if segJobs == nil || len(buffer)-segStart < segSamples {
+		return
+	}
+
+	end := quietestCut(buffer, segStart+segSamples)
+	samples := append([]int16(nil), buffer[segStart:end]...)
+	segJobs <- segmentJob{Segment: Segment{Index: segIndex}, samples: samples}
+	segStart = end
+	segIndex++
+}
+
+// flushSegments hands off the rest of the buffer as the final segment and
+// waits until every segment has been written and handled. It reports false
+// when the recording wasn't segmented.
+func flushSegments() bool {
+	segMu.Lock()
+	jobs, done := segJobs, segDone
+	if jobs == nil {
+		segMu.Unlock()
+		return false
+	}
+	if rest := buffer[min(segStart, len(buffer)):]; len(rest) >= minFinalSamples {
+		samples := append([]int16(nil), rest...)
+		jobs <- segmentJob{Segment: Segment{Index: segIndex, Final: true}, samples: samples}
+	}
+	close(jobs)
+	segJobs, segDone = nil, nil
+	segMu.Unlock()
+
+	<-done
+	return true
+}
+
+// writeSegments writes queued segments to disk off the recording goroutine
+func writeSegments(base string, jobs <-chan segmentJob, done chan<- struct{}, onSegment SegmentHandler) {
+	defer close(done)
+
+	for job := range jobs {
+		path := fmt.Sprintf("%s%02d.wav", base, job.Index)
+		if err := writeWav(path, job.samples); err != nil {
+			fmt.Printf("Warning: failed to write audio segment %d: %v\n", job.Index, err)
+			continue
+		}
+		job.Path = path
+		onSegment(job.Segment)
+	}
+}
+
+// quietestCut returns the start of the quietest 20ms frame in the half second
+// before target, so segments tend to end between words
+func quietestCut(samples []int16, target int) int {
+	const frame = sampleRate / 50
+
+	best, bestEnergy := target, int64(-1)
+	for start := target - quietWindow; start+frame <= target; start += frame {
+		var energy int64
+		for _, sample := range samples[start : start+frame] {
+			energy += int64(sample) * int64(sample)
+		}
+		if bestEnergy < 0 || energy < bestEnergy {
+			best, bestEnergy = start, energy
+		}
+	}
+	return best
+}
+
+func writeWav(path string, samples []int16) error {
+	if err := os.MkdirAll(filepath.Dir(path), FOLDER_PERMS_READALL_WRITEOWN); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writeWavHeader(file, len(samples))
+	return binary.Write(file, binary.LittleEndian, samples)
+}
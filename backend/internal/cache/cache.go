@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+const FOLDER_PERMS_READALL_WRITEOWN = 0755
+
+// Dir is where cached answers are stored, one JSON file per key
+var Dir = filepath.Join(".data", "cache")
+
+// Entry is a stored answer
+type Entry struct {
+	Key     string    `json:"key"`
+	Created time.Time `json:"created"`
+	Model   string    `json:"model,omitempty"`
+	Profile string    `json:"profile,omitempty"`
+	Answer  string    `json:"answer"`
+}
+
+// Cache is a content-addressed answer cache on disk
+type Cache struct {
+	dir        string
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+	mu         sync.Mutex
+}
+
+// New creates a cache in Dir with the limits from cfg
+func New(cfg config.CacheConfig) *Cache {
+	return &Cache{
+		dir:        Dir,
+		ttl:        time.Duration(cfg.TTLMinutes) * time.Minute,
+		maxEntries: cfg.MaxEntries,
+		maxBytes:   int64(cfg.MaxMB) * 1024 * 1024,
+	}
+}
+
+// Key hashes the parts into a cache key. Parts are length-prefixed so
+// different splits of the same text never collide.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the entry for key if it exists and hasn't expired
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return nil, false
+	}
+	if c.expired(entry.Created) {
+		os.Remove(c.path(key))
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Put stores an entry and evicts expired and old entries beyond the limits
+func (c *Cache) Put(entry Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.dir, FOLDER_PERMS_READALL_WRITEOWN); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	if entry.Created.IsZero() {
+		entry.Created = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial entry
+	tmp := c.path(entry.Key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path(entry.Key)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return c.prune()
+}
+
+// prune removes expired entries, then the oldest ones until the cache is
+// within its entry and size limits. Caller must hold c.mu.
+func (c *Cache) prune() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, dirEntry.Name())
+		if c.expired(info.ModTime()) {
+			os.Remove(path)
+			continue
+		}
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	// Newest first, so the oldest are trimmed from the end
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	for len(files) > 0 &&
+		((c.maxEntries > 0 && len(files) > c.maxEntries) || (c.maxBytes > 0 && total > c.maxBytes)) {
+		oldest := files[len(files)-1]
+		if err := os.Remove(oldest.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		total -= oldest.size
+		files = files[:len(files)-1]
+	}
+	return nil
+}
+
+func (c *Cache) expired(created time.Time) bool {
+	return c.ttl > 0 && time.Since(created) > c.ttl
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
@@ -1,6 +1,7 @@
 package capture
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -42,8 +43,8 @@ func (m *Manager) TriggerScreenshot() error {
 
 	fmt.Println("📸 Remote screenshot triggered...")
 
-	// Capture screenshot
-	screenshotPath, err := screen.CaptureScreenshot()
+	// Capture screenshots of every configured display
+	screenshots, err := screen.CaptureScreenshots()
 	if err != nil {
 		return fmt.Errorf("screenshot failed: %w", err)
 	}
@@ -51,7 +52,7 @@ func (m *Manager) TriggerScreenshot() error {
 	fmt.Println("✅ Screenshot captured, processing...")
 
 	// Process with OpenAI (no audio)
-	if err := m.session.Process(screenshotPath, "", false); err != nil {
+	if err := m.session.Process(context.Background(), openai.Input{Screenshots: screenshots}, false); err != nil {
 		return fmt.Errorf("processing failed: %w", err)
 	}
 
@@ -0,0 +1,947 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/imageproc"
+)
+
+// RulesPath is the location of the rules.json file, relative to the working directory
+var RulesPath = filepath.Join(projectRoot(), "rules.json")
+
+func projectRoot() string {
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// Rules mirrors the user-editable rules.json file at the project root
+type Rules struct {
+	// WhatDoYouNeedHelpWith is appended to the system prompt
+	WhatDoYouNeedHelpWith string `json:"whatDoYouNeedHelpWith"`
+	// SystemPrompt replaces the built-in system prompt; it is a text/template
+	SystemPrompt string `json:"systemPrompt"`
+	// SystemPromptFile loads SystemPrompt from a file, relative to rules.json
+	SystemPromptFile string `json:"systemPromptFile"`
+	// Vars are user-defined values the prompt template can use as .Vars.name
+	Vars map[string]string `json:"vars"`
+	// Display is the macOS display number passed to screencapture
+	Display int `json:"display"`
+	// Displays captures several displays per turn, overriding Display
+	Displays []int `json:"displays"`
+	// IncludePrevious also sends the previous capture so the model can compare
+	IncludePrevious bool `json:"includePrevious"`
+	// Provider selects the LLM backend (defaults to "openai")
+	Provider string `json:"provider"`
+	// Endpoint points the provider at a specific server and models
+	Endpoint EndpointConfig `json:"endpoint"`
+	// Chat tunes the chat completion request
+	Chat ChatParams `json:"chat"`
+	// History bounds how much conversation is sent with each request
+	History HistoryConfig `json:"history"`
+	// Retry controls retries of failed provider calls
+	Retry RetryConfig `json:"retry"`
+	// Image controls screenshot preprocessing before upload
+	Image ImageConfig `json:"image"`
+	// Tools lets the model call local tools during a turn
+	Tools ToolsConfig `json:"tools"`
+	// OCR extracts screen text, which replaces the image for models without vision
+	OCR OCRConfig `json:"ocr"`
+	// Transcription selects the speech-to-text backend
+	Transcription TranscriptionConfig `json:"transcription"`
+	// TTS speaks answers aloud
+	TTS TTSConfig `json:"tts"`
+	// Structured asks for answers as JSON matching a schema instead of markdown
+	Structured *StructuredConfig `json:"structured"`
+	// Cache replays stored answers for an unchanged screen and question
+	Cache CacheConfig `json:"cache"`
+	// Redaction removes secrets and personal data before anything is uploaded
+	Redaction RedactionConfig `json:"redaction"`
+	// Queue keeps captures that failed to send and retries them later
+	Queue QueueConfig `json:"queue"`
+	// Prices extend or override the built-in price table used by `assistant usage`
+	Prices map[string]ModelPrice `json:"prices"`
+	// Profiles are named presets that override the prompt, model and preprocessing
+	Profiles map[string]Profile `json:"profiles"`
+	// Profile is the profile used when none is selected on the command line
+	Profile string `json:"profile"`
+
+	// ActiveProfile is the profile applied by ForProfile ("" for none)
+	ActiveProfile string `json:"-"`
+}
+
+// Profile overrides parts of the rules for one kind of task.
+// Unset fields keep the top-level setting.
+type Profile struct {
+	// Prompt replaces whatDoYouNeedHelpWith
+	Prompt string `json:"prompt"`
+	// Model replaces chat.model
+	Model string `json:"model"`
+	// Vision replaces chat.vision
+	Vision *bool `json:"vision"`
+	// Chat overrides individual model parameters
+	Chat *ChatOverride `json:"chat"`
+	// Image replaces the image section as a whole
+	Image *ImageConfig `json:"image"`
+	// Structured replaces the structured section
+	Structured *StructuredConfig `json:"structured"`
+}
+
+// ChatOverride replaces the chat parameters a profile sets. Unset fields keep
+// the top-level value; the pointers let a profile set 0 explicitly.
+type ChatOverride struct {
+	Temperature *float64 `json:"temperature"`
+	MaxTokens   *int64   `json:"maxTokens"`
+	ImageDetail string   `json:"imageDetail"`
+}
+
+// apply copies the set fields onto params
+func (o *ChatOverride) apply(params *ChatParams) {
+	if o.Temperature != nil {
+		params.Temperature = o.Temperature
+	}
+	if o.MaxTokens != nil {
+		params.MaxTokens = *o.MaxTokens
+	}
+	if o.ImageDetail != "" {
+		params.ImageDetail = o.ImageDetail
+	}
+}
+
+// EndpointConfig configures where the provider sends requests.
+// Leaving BaseURL empty uses the vendor's hosted API.
+type EndpointConfig struct {
+	BaseURL            string `json:"baseUrl"`
+	APIKey             string `json:"apiKey"`
+	ChatModel          string `json:"chatModel"`
+	TranscriptionModel string `json:"transcriptionModel"`
+}
+
+// Image detail levels accepted by vision models
+const (
+	ImageDetailAuto = "auto"
+	ImageDetailLow  = "low"
+	ImageDetailHigh = "high"
+)
+
+// ChatParams holds the model parameters sent with every chat request.
+// Zero values mean "use the provider default".
+type ChatParams struct {
+	// Model overrides endpoint.chatModel for chat requests
+	Model string `json:"model"`
+	// Temperature is a pointer so an explicit 0 can be told apart from unset
+	Temperature *float64 `json:"temperature"`
+	// MaxTokens caps the completion length (0 means no limit)
+	MaxTokens int64 `json:"maxTokens"`
+	// ImageDetail is one of "auto", "low" or "high" (defaults to "auto")
+	ImageDetail string `json:"imageDetail"`
+	// Vision is false for models that cannot accept images (defaults to true)
+	Vision *bool `json:"vision"`
+}
+
+// SupportsVision reports whether screenshots can be sent as images
+func (c ChatParams) SupportsVision() bool {
+	return c.Vision == nil || *c.Vision
+}
+
+// Validate checks the parameters and fills in defaults
+func (c *ChatParams) Validate() error {
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > 2) {
+		return fmt.Errorf("chat.temperature must be between 0 and 2, got %v", *c.Temperature)
+	}
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("chat.maxTokens must not be negative, got %d", c.MaxTokens)
+	}
+
+	switch c.ImageDetail {
+	case "":
+		c.ImageDetail = ImageDetailAuto
+	case ImageDetailAuto, ImageDetailLow, ImageDetailHigh:
+	default:
+		return fmt.Errorf("chat.imageDetail must be one of auto, low, high, got %q", c.ImageDetail)
+	}
+	return nil
+}
+
+// Defaults for HistoryConfig
+const (
+	DefaultHistoryMaxTokens       = 60000
+	DefaultHistoryKeepImages      = 1
+	DefaultHistoryKeepRecentTurns = 4
+)
+
+// HistoryConfig keeps long-running sessions within a token budget
+type HistoryConfig struct {
+	// MaxTokens is the estimated prompt budget before older turns are summarized
+	MaxTokens int `json:"maxTokens"`
+	// KeepImages is how many of the most recent user turns keep their screenshots.
+	// It is a pointer so an explicit 0 (keep none) can be told apart from unset.
+	KeepImages *int `json:"keepImages"`
+	// KeepRecentTurns is how many recent turns are kept verbatim when summarizing
+	// (0 summarizes them all)
+	KeepRecentTurns *int `json:"keepRecentTurns"`
+	// SummaryModel is used for summarization (defaults to the chat model)
+	SummaryModel string `json:"summaryModel"`
+}
+
+// ImagesKept returns KeepImages, or its default when unset
+func (c HistoryConfig) ImagesKept() int {
+	if c.KeepImages == nil {
+		return DefaultHistoryKeepImages
+	}
+	return *c.KeepImages
+}
+
+// RecentTurnsKept returns KeepRecentTurns, or its default when unset
+func (c HistoryConfig) RecentTurnsKept() int {
+	if c.KeepRecentTurns == nil {
+		return DefaultHistoryKeepRecentTurns
+	}
+	return *c.KeepRecentTurns
+}
+
+// Validate checks the history settings and fills in defaults
+func (c *HistoryConfig) Validate() error {
+	if c.MaxTokens < 0 || c.ImagesKept() < 0 || c.RecentTurnsKept() < 0 {
+		return fmt.Errorf("history values must not be negative")
+	}
+	if c.MaxTokens == 0 {
+		c.MaxTokens = DefaultHistoryMaxTokens
+	}
+	return nil
+}
+
+// Defaults for RetryConfig
+const (
+	DefaultRetryMaxAttempts   = 3
+	DefaultRetryBaseDelayMs   = 500
+	DefaultRetryMaxDelayMs    = 8000
+	DefaultRetryMaxRetryAfter = 60
+)
+
+// RetryConfig controls retries of rate limited or failed provider calls
+type RetryConfig struct {
+	// MaxAttempts is the total number of tries; 1 disables retries
+	MaxAttempts int `json:"maxAttempts"`
+	// BaseDelayMs is the first backoff, doubled on every retry
+	BaseDelayMs int `json:"baseDelayMs"`
+	// MaxDelayMs caps the backoff
+	MaxDelayMs int `json:"maxDelayMs"`
+	// MaxRetryAfterSec is the longest server-requested wait that will be honoured
+	MaxRetryAfterSec int `json:"maxRetryAfterSec"`
+}
+
+// Validate checks the retry settings and fills in defaults
+func (c *RetryConfig) Validate() error {
+	if c.MaxAttempts < 0 || c.BaseDelayMs < 0 || c.MaxDelayMs < 0 || c.MaxRetryAfterSec < 0 {
+		return fmt.Errorf("retry values must not be negative")
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if c.BaseDelayMs == 0 {
+		c.BaseDelayMs = DefaultRetryBaseDelayMs
+	}
+	if c.MaxDelayMs == 0 {
+		c.MaxDelayMs = DefaultRetryMaxDelayMs
+	}
+	if c.MaxRetryAfterSec == 0 {
+		c.MaxRetryAfterSec = DefaultRetryMaxRetryAfter
+	}
+	if c.MaxDelayMs < c.BaseDelayMs {
+		return fmt.Errorf("retry.maxDelayMs (%d) must not be less than retry.baseDelayMs (%d)", c.MaxDelayMs, c.BaseDelayMs)
+	}
+	return nil
+}
+
+// DefaultImageMaxDimension matches the largest size vision models look at
+const DefaultImageMaxDimension = 2048
+
+// ImageConfig controls how screenshots are preprocessed before upload
+type ImageConfig struct {
+	// MaxDimension downscales the longest side to this many pixels
+	MaxDimension int `json:"maxDimension"`
+	// Grayscale drops color, which shrinks the upload
+	Grayscale bool `json:"grayscale"`
+	// Crop keeps only this region of the screenshot, in screenshot pixels
+	Crop imageproc.Rect `json:"crop"`
+	// Quality is the JPEG quality, 1-100
+	Quality int `json:"quality"`
+}
+
+// Validate checks the image settings and fills in defaults
+func (c *ImageConfig) Validate() error {
+	if c.MaxDimension < 0 {
+		return fmt.Errorf("image.maxDimension must not be negative, got %d", c.MaxDimension)
+	}
+	if c.Quality < 0 || c.Quality > 100 {
+		return fmt.Errorf("image.quality must be between 1 and 100, got %d", c.Quality)
+	}
+	if c.Crop.X < 0 || c.Crop.Y < 0 || c.Crop.Width < 0 || c.Crop.Height < 0 {
+		return fmt.Errorf("image.crop values must not be negative")
+	}
+	if c.MaxDimension == 0 {
+		c.MaxDimension = DefaultImageMaxDimension
+	}
+	if c.Quality == 0 {
+		c.Quality = imageproc.DefaultQuality
+	}
+	return nil
+}
+
+// Options converts the config into imageproc options
+func (c ImageConfig) Options() imageproc.Options {
+	return imageproc.Options{
+		Crop:         c.Crop,
+		MaxDimension: c.MaxDimension,
+		Grayscale:    c.Grayscale,
+		Quality:      c.Quality,
+	}
+}
+
+// Defaults for ToolsConfig
+const (
+	DefaultToolsMaxRounds      = 5
+	DefaultToolsMaxOutputBytes = 16 * 1024
+	DefaultToolsTimeoutSec     = 10
+)
+
+// ToolsConfig enables local tools. Every tool is off unless configured.
+type ToolsConfig struct {
+	// Enabled turns tool calling on
+	Enabled bool `json:"enabled"`
+	// AllowedDirs enables read_file for files under these directories
+	AllowedDirs []string `json:"allowedDirs"`
+	// Clipboard enables read_clipboard
+	Clipboard bool `json:"clipboard"`
+	// RecentCaptures enables list_recent_captures
+	RecentCaptures bool `json:"recentCaptures"`
+	// AllowedCommands are the executables run_command may run (no shell)
+	AllowedCommands []string `json:"allowedCommands"`
+	// RunCommand enables run_command. The model sees screen content, so text
+	// on screen can make it run these commands without asking.
+	RunCommand bool `json:"runCommand"`
+	// MaxRounds limits tool round-trips per turn
+	MaxRounds int `json:"maxRounds"`
+	// MaxOutputBytes truncates each tool result
+	MaxOutputBytes int `json:"maxOutputBytes"`
+	// TimeoutSec bounds run_command and read_clipboard
+	TimeoutSec int `json:"timeoutSec"`
+}
+
+// Validate checks the tool settings and fills in defaults
+func (c *ToolsConfig) Validate() error {
+	if c.MaxRounds < 0 || c.MaxOutputBytes < 0 || c.TimeoutSec < 0 {
+		return fmt.Errorf("tools values must not be negative")
+	}
+	for _, command := range c.AllowedCommands {
+		if command == "" || filepath.Base(command) != command {
+			return fmt.Errorf("tools.allowedCommands entries must be bare executable names, got %q", command)
+		}
+	}
+	if c.MaxRounds == 0 {
+		c.MaxRounds = DefaultToolsMaxRounds
+	}
+	if c.MaxOutputBytes == 0 {
+		c.MaxOutputBytes = DefaultToolsMaxOutputBytes
+	}
+	if c.TimeoutSec == 0 {
+		c.TimeoutSec = DefaultToolsTimeoutSec
+	}
+	return nil
+}
+
+// OCR engines
+const (
+	OCREngineNone      = "none"
+	OCREngineTesseract = "tesseract"
+)
+
+// Defaults for OCRConfig
+const (
+	DefaultOCRCommand    = "tesseract"
+	DefaultOCRLanguages  = "eng"
+	DefaultOCRTimeoutSec = 30
+)
+
+// OCRConfig selects how text is extracted from screenshots
+type OCRConfig struct {
+	// Engine is "none" (default) or "tesseract"
+	Engine string `json:"engine"`
+	// Command is the tesseract executable
+	Command string `json:"command"`
+	// Languages are tesseract language codes joined by "+", e.g. "eng+deu"
+	Languages string `json:"languages"`
+	// TimeoutSec bounds a single recognition
+	TimeoutSec int `json:"timeoutSec"`
+}
+
+// Validate checks the OCR settings and fills in defaults
+func (c *OCRConfig) Validate() error {
+	switch c.Engine {
+	case "":
+		c.Engine = OCREngineNone
+	case OCREngineNone, OCREngineTesseract:
+	default:
+		return fmt.Errorf("ocr.engine must be one of none, tesseract, got %q", c.Engine)
+	}
+	if c.TimeoutSec < 0 {
+		return fmt.Errorf("ocr.timeoutSec must not be negative, got %d", c.TimeoutSec)
+	}
+	if c.Command == "" {
+		c.Command = DefaultOCRCommand
+	}
+	if c.Languages == "" {
+		c.Languages = DefaultOCRLanguages
+	}
+	if c.TimeoutSec == 0 {
+		c.TimeoutSec = DefaultOCRTimeoutSec
+	}
+	return nil
+}
+
+// Transcription backends
+const (
+	TranscriptionOpenAI           = "openai"
+	TranscriptionOpenAICompatible = "openai-compatible"
+	TranscriptionWhisperCpp       = "whisper.cpp"
+)
+
+// Defaults for TranscriptionConfig
+const (
+	DefaultWhisperCppCommand       = "whisper-cli"
+	DefaultTranscriptionTimeoutSec = 120
+	DefaultTranscriptionSegmentSec = 4
+)
+
+// TranscriptionConfig selects how recorded questions are turned into text
+type TranscriptionConfig struct {
+	// Backend is "openai" (default, the configured provider), "openai-compatible" or "whisper.cpp"
+	Backend string `json:"backend"`
+	// Model overrides endpoint.transcriptionModel; for whisper.cpp it is the path to a ggml model file
+	Model string `json:"model"`
+	// BaseURL is the server of the openai-compatible backend
+	BaseURL string `json:"baseUrl"`
+	// APIKey is sent to the openai-compatible backend, if it needs one
+	APIKey string `json:"apiKey"`
+	// Command is the whisper.cpp executable
+	Command string `json:"command"`
+	// Language is the ISO-639-1 code of the speech, e.g. "en"; empty auto-detects
+	Language string `json:"language"`
+	// Prompt hints names and jargon the model should expect
+	Prompt string `json:"prompt"`
+	// Temperature is a pointer so an explicit 0 can be told apart from unset
+	Temperature *float64 `json:"temperature"`
+	// TimeoutSec bounds a single whisper.cpp run
+	TimeoutSec int `json:"timeoutSec"`
+	// Streaming transcribes the recording in segments while the key is held (defaults to true)
+	Streaming *bool `json:"streaming"`
+	// SegmentSec is the length of a streamed segment
+	SegmentSec int `json:"segmentSec"`
+}
+
+// StreamingEnabled reports whether recordings are transcribed while they are made
+func (c TranscriptionConfig) StreamingEnabled() bool {
+	return c.Streaming == nil || *c.Streaming
+}
+
+// Validate checks the transcription settings and fills in defaults
+func (c *TranscriptionConfig) Validate() error {
+	switch c.Backend {
+	case "":
+		c.Backend = TranscriptionOpenAI
+	case TranscriptionOpenAI:
+	case TranscriptionOpenAICompatible:
+		if c.BaseURL == "" {
+			return fmt.Errorf("transcription.baseUrl is required for the %s backend", c.Backend)
+		}
+	case TranscriptionWhisperCpp:
+		if c.Model == "" {
+			return fmt.Errorf("transcription.model must be the path to a ggml model for the %s backend", c.Backend)
+		}
+	default:
+		return fmt.Errorf("transcription.backend must be one of openai, openai-compatible, whisper.cpp, got %q", c.Backend)
+	}
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > 1) {
+		return fmt.Errorf("transcription.temperature must be between 0 and 1, got %v", *c.Temperature)
+	}
+	if c.TimeoutSec < 0 {
+		return fmt.Errorf("transcription.timeoutSec must not be negative, got %d", c.TimeoutSec)
+	}
+	if c.SegmentSec < 0 {
+		return fmt.Errorf("transcription.segmentSec must not be negative, got %d", c.SegmentSec)
+	}
+	if c.Command == "" {
+		c.Command = DefaultWhisperCppCommand
+	}
+	if c.TimeoutSec == 0 {
+		c.TimeoutSec = DefaultTranscriptionTimeoutSec
+	}
+	if c.SegmentSec == 0 {
+		c.SegmentSec = DefaultTranscriptionSegmentSec
+	}
+	return nil
+}
+
+// TTS backends
+const (
+	TTSOpenAI = "openai"
+	TTSEspeak = "espeak"
+	TTSPiper  = "piper"
+	TTSFile   = "file"
+)
+
+// Defaults for TTSConfig
+const (
+	DefaultTTSOpenAIModel = "gpt-4o-mini-tts"
+	DefaultTTSOpenAIVoice = "alloy"
+	DefaultTTSEspeak      = "espeak-ng"
+	DefaultTTSPiper       = "piper"
+	DefaultTTSPlayer      = "afplay"
+	DefaultTTSPath        = ".data/tts/spoken.txt"
+)
+
+// TTSConfig controls spoken answers
+type TTSConfig struct {
+	// Enabled speaks every answer; `listen --speak` turns it on too
+	Enabled bool `json:"enabled"`
+	// Backend is "openai" (default), "espeak", "piper" or "file"
+	Backend string `json:"backend"`
+	// Model is the OpenAI speech model, or the path to a piper .onnx voice
+	Model string `json:"model"`
+	// Voice is the OpenAI or espeak voice
+	Voice string `json:"voice"`
+	// Speed scales the speaking rate (defaults to 1)
+	Speed float64 `json:"speed"`
+	// Command is the espeak or piper executable
+	Command string `json:"command"`
+	// Player plays the audio synthesized by the openai and piper backends
+	Player string `json:"player"`
+	// Path is where the file backend appends the sentences it would speak
+	Path string `json:"path"`
+}
+
+// Validate checks the TTS settings and fills in defaults
+func (c *TTSConfig) Validate() error {
+	switch c.Backend {
+	case "":
+		c.Backend = TTSOpenAI
+	case TTSOpenAI, TTSEspeak, TTSFile:
+	case TTSPiper:
+		if c.Model == "" {
+			return fmt.Errorf("tts.model must be the path to a piper voice for the %s backend", c.Backend)
+		}
+	default:
+		return fmt.Errorf("tts.backend must be one of openai, espeak, piper, file, got %q", c.Backend)
+	}
+	if c.Speed != 0 && (c.Speed < 0.25 || c.Speed > 4) {
+		return fmt.Errorf("tts.speed must be between 0.25 and 4, got %v", c.Speed)
+	}
+
+	if c.Speed == 0 {
+		c.Speed = 1
+	}
+	if c.Backend == TTSOpenAI {
+		if c.Model == "" {
+			c.Model = DefaultTTSOpenAIModel
+		}
+		if c.Voice == "" {
+			c.Voice = DefaultTTSOpenAIVoice
+		}
+	}
+	if c.Command == "" {
+		switch c.Backend {
+		case TTSEspeak:
+			c.Command = DefaultTTSEspeak
+		case TTSPiper:
+			c.Command = DefaultTTSPiper
+		}
+	}
+	if c.Player == "" {
+		c.Player = DefaultTTSPlayer
+	}
+	if c.Path == "" {
+		c.Path = DefaultTTSPath
+	}
+	return nil
+}
+
+// Defaults for CacheConfig
+const (
+	DefaultCacheTTLMinutes = 24 * 60
+	DefaultCacheMaxEntries = 500
+	DefaultCacheMaxMB      = 50
+)
+
+// CacheConfig controls the answer cache
+type CacheConfig struct {
+	// Enabled turns the cache on
+	Enabled bool `json:"enabled"`
+	// TTLMinutes is how long an answer stays valid
+	TTLMinutes int `json:"ttlMinutes"`
+	// MaxEntries caps the number of stored answers
+	MaxEntries int `json:"maxEntries"`
+	// MaxMB caps the total size of the cache
+	MaxMB int `json:"maxMB"`
+}
+
+// Validate checks the cache settings and fills in defaults
+func (c *CacheConfig) Validate() error {
+	if c.TTLMinutes < 0 || c.MaxEntries < 0 || c.MaxMB < 0 {
+		return fmt.Errorf("cache values must not be negative")
+	}
+	if c.TTLMinutes == 0 {
+		c.TTLMinutes = DefaultCacheTTLMinutes
+	}
+	if c.MaxEntries == 0 {
+		c.MaxEntries = DefaultCacheMaxEntries
+	}
+	if c.MaxMB == 0 {
+		c.MaxMB = DefaultCacheMaxMB
+	}
+	return nil
+}
+
+// Built-in redaction detectors
+const (
+	RedactAPIKeys = "apiKeys"
+	RedactEmails  = "emails"
+	RedactCards   = "cards"
+)
+
+// RedactionDetectors lists the built-in detectors, used when none are configured
+var RedactionDetectors = []string{RedactAPIKeys, RedactEmails, RedactCards}
+
+// RedactionRule replaces every match of a regular expression. When the
+// pattern has a group named "secret", only that group is replaced.
+type RedactionRule struct {
+	// Name identifies the rule in the audit log
+	Name string `json:"name"`
+	// Pattern is a Go regular expression
+	Pattern string `json:"pattern"`
+	// Replacement defaults to "[REDACTED:<name>]"
+	Replacement string `json:"replacement"`
+}
+
+// RedactionConfig controls what is removed from captures before upload
+type RedactionConfig struct {
+	// Enabled turns redaction on
+	Enabled bool `json:"enabled"`
+	// Detectors selects built-in detectors (defaults to all of them)
+	Detectors []string `json:"detectors"`
+	// Rules are extra patterns to redact from text
+	Rules []RedactionRule `json:"rules"`
+	// Masks are blacked out in every screenshot, in screenshot pixels
+	Masks []imageproc.Rect `json:"masks"`
+}
+
+// Validate checks the redaction settings and fills in defaults
+func (c *RedactionConfig) Validate() error {
+	for _, name := range c.Detectors {
+		if !slices.Contains(RedactionDetectors, name) {
+			return fmt.Errorf("unknown redaction detector %q (use %s)", name, strings.Join(RedactionDetectors, ", "))
+		}
+	}
+	for i, rule := range c.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("redaction.rules[%d] needs a name", i)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("redaction rule %q: %w", rule.Name, err)
+		}
+		if rule.Pattern == "" {
+			return fmt.Errorf("redaction rule %q needs a pattern", rule.Name)
+		}
+	}
+	for _, mask := range c.Masks {
+		if mask.X < 0 || mask.Y < 0 || mask.Empty() {
+			return fmt.Errorf("redaction.masks must have a positive size and no negative values, got %+v", mask)
+		}
+	}
+	if c.Detectors == nil {
+		c.Detectors = RedactionDetectors
+	}
+	return nil
+}
+
+// Defaults for QueueConfig
+const (
+	DefaultQueueRetrySec    = 30
+	DefaultQueueMaxAttempts = 20
+)
+
+// QueueConfig controls the offline capture queue
+type QueueConfig struct {
+	// Enabled is a pointer so the queue can default to on
+	Enabled *bool `json:"enabled"`
+	// RetrySec is how often `listen` retries queued captures
+	RetrySec int `json:"retrySec"`
+	// MaxAttempts stops automatic retries of a capture; `queue retry` still works
+	MaxAttempts int `json:"maxAttempts"`
+}
+
+// IsEnabled reports whether failed captures are queued
+func (c QueueConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// Validate checks the queue settings and fills in defaults
+func (c *QueueConfig) Validate() error {
+	if c.RetrySec < 0 || c.MaxAttempts < 0 {
+		return fmt.Errorf("queue values must not be negative")
+	}
+	if c.RetrySec == 0 {
+		c.RetrySec = DefaultQueueRetrySec
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = DefaultQueueMaxAttempts
+	}
+	return nil
+}
+
+// ModelPrice is what a model costs, in USD. Chat models are priced per
+// million tokens and transcription models per minute of audio.
+type ModelPrice struct {
+	InputPerMTok       float64 `json:"inputPerMTok"`
+	CachedInputPerMTok float64 `json:"cachedInputPerMTok"`
+	OutputPerMTok      float64 `json:"outputPerMTok"`
+	PerAudioMinute     float64 `json:"perAudioMinute"`
+}
+
+// DefaultStructuredName is the schema name sent when none is configured
+const DefaultStructuredName = "answer"
+
+// StructuredConfig declares the JSON schema of structured answers
+type StructuredConfig struct {
+	// Name identifies the schema to the model (letters, digits, _ and -)
+	Name string `json:"name"`
+	// Description tells the model what the answer is for
+	Description string `json:"description"`
+	// Schema is a JSON schema whose root must be an object
+	Schema map[string]any `json:"schema"`
+	// Strict enables strict schema adherence; the schema must then list every
+	// property as required and set additionalProperties to false
+	Strict bool `json:"strict"`
+}
+
+// Validate checks the schema and fills in defaults
+func (c *StructuredConfig) Validate() error {
+	if c.Name == "" {
+		c.Name = DefaultStructuredName
+	}
+	for _, r := range c.Name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return fmt.Errorf("structured.name may only contain letters, digits, _ and -, got %q", c.Name)
+		}
+	}
+	if len(c.Schema) == 0 {
+		return fmt.Errorf("structured.schema is required")
+	}
+	if c.Schema["type"] != "object" {
+		return fmt.Errorf("structured.schema must have \"type\": \"object\" at the root")
+	}
+	return nil
+}
+
+// Load reads rules.json. A missing or malformed file yields the defaults, with a
+// warning for the latter, but invalid settings are reported to the caller.
+func Load() (*Rules, error) {
+	rules := &Rules{}
+
+	rulesBytes, err := os.ReadFile(RulesPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return rules, rules.Validate()
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", RulesPath, err)
+	}
+
+	if err := json.Unmarshal(rulesBytes, rules); err != nil {
+		// Malformed files used to be ignored, and older copies of the template
+		// aren't valid JSON, so keep starting with the defaults
+		fmt.Printf("Warning: failed to parse %s, using the defaults: %v\n", RulesPath, err)
+		rules = &Rules{}
+		return rules, rules.Validate()
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RulesPath, err)
+	}
+
+	return rules, nil
+}
+
+// Validate checks every section of the rules and fills in defaults
+func (r *Rules) Validate() error {
+	if err := r.loadSystemPrompt(); err != nil {
+		return err
+	}
+	for _, display := range r.Displays {
+		if display <= 0 {
+			return fmt.Errorf("displays must be positive display numbers, got %d", display)
+		}
+	}
+	if err := r.Chat.Validate(); err != nil {
+		return err
+	}
+	if err := r.History.Validate(); err != nil {
+		return err
+	}
+	if err := r.Retry.Validate(); err != nil {
+		return err
+	}
+	if err := r.Image.Validate(); err != nil {
+		return err
+	}
+	if err := r.Tools.Validate(); err != nil {
+		return err
+	}
+	if err := r.OCR.Validate(); err != nil {
+		return err
+	}
+	if err := r.Transcription.Validate(); err != nil {
+		return err
+	}
+	if err := r.TTS.Validate(); err != nil {
+		return err
+	}
+	if err := r.Cache.Validate(); err != nil {
+		return err
+	}
+	if err := r.Redaction.Validate(); err != nil {
+		return err
+	}
+	if err := r.Queue.Validate(); err != nil {
+		return err
+	}
+	if r.Structured != nil {
+		if err := r.Structured.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for model, price := range r.Prices {
+		if price.InputPerMTok < 0 || price.CachedInputPerMTok < 0 || price.OutputPerMTok < 0 || price.PerAudioMinute < 0 {
+			return fmt.Errorf("prices for %q must not be negative", model)
+		}
+	}
+
+	for name, profile := range r.Profiles {
+		if name == "" {
+			return fmt.Errorf("profile names must not be empty")
+		}
+		if profile.Chat != nil {
+			chat := r.Chat
+			profile.Chat.apply(&chat)
+			if err := chat.Validate(); err != nil {
+				return fmt.Errorf("profile %q: %w", name, err)
+			}
+		}
+		if profile.Image != nil {
+			if err := profile.Image.Validate(); err != nil {
+				return fmt.Errorf("profile %q: %w", name, err)
+			}
+		}
+		if profile.Structured != nil {
+			if err := profile.Structured.Validate(); err != nil {
+				return fmt.Errorf("profile %q: %w", name, err)
+			}
+		}
+		r.Profiles[name] = profile
+	}
+	if r.Profile != "" {
+		if _, ok := r.Profiles[r.Profile]; !ok {
+			return fmt.Errorf("profile %q is not defined in profiles", r.Profile)
+		}
+	}
+	return nil
+}
+
+// loadSystemPrompt reads systemPromptFile and checks that the template parses
+func (r *Rules) loadSystemPrompt() error {
+	if r.SystemPromptFile != "" {
+		if r.SystemPrompt != "" {
+			return fmt.Errorf("set either systemPrompt or systemPromptFile, not both")
+		}
+		path := r.SystemPromptFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(RulesPath), path)
+		}
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read systemPromptFile: %w", err)
+		}
+		r.SystemPrompt = string(text)
+		r.SystemPromptFile = ""
+	}
+
+	if r.SystemPrompt != "" {
+		if _, err := template.New("systemPrompt").Parse(r.SystemPrompt); err != nil {
+			return fmt.Errorf("invalid systemPrompt template: %w", err)
+		}
+	}
+	return nil
+}
+
+// ProfileNames returns the defined profiles in alphabetical order
+func (r *Rules) ProfileNames() []string {
+	names := make([]string, 0, len(r.Profiles))
+	for name := range r.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForProfile returns a copy of the rules with the named profile applied.
+// An empty name returns the rules without any profile.
+func (r *Rules) ForProfile(name string) (*Rules, error) {
+	out := *r
+	out.ActiveProfile = name
+	if name == "" {
+		return &out, nil
+	}
+
+	profile, ok := r.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(r.ProfileNames(), ", "))
+	}
+	if profile.Prompt != "" {
+		out.WhatDoYouNeedHelpWith = profile.Prompt
+	}
+	if profile.Model != "" {
+		out.Chat.Model = profile.Model
+	}
+	if profile.Vision != nil {
+		out.Chat.Vision = profile.Vision
+	}
+	if profile.Chat != nil {
+		profile.Chat.apply(&out.Chat)
+	}
+	if profile.Image != nil {
+		out.Image = *profile.Image
+	}
+	if profile.Structured != nil {
+		out.Structured = profile.Structured
+	}
+	return &out, nil
+}
+
+// CaptureDisplays returns the displays to capture each turn (display 1 by default)
+func (r *Rules) CaptureDisplays() []int {
+	if len(r.Displays) > 0 {
+		return r.Displays
+	}
+	if r.Display > 0 {
+		return []int{r.Display}
+	}
+	return []int{1}
+}
@@ -0,0 +1,197 @@
+package conversation
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/screen"
+)
+
+const FOLDER_PERMS_READALL_WRITEOWN = 0755
+
+// Dir is where conversation logs are stored, one JSONL file per session
+var Dir = filepath.Join(".data", "sessions")
+
+// Turn is one persisted request/response exchange
+type Turn struct {
+	Time time.Time `json:"time"`
+	// Screenshots and AudioPath point at the capture artifacts under .data
+	Screenshots []screen.Screenshot `json:"screenshots,omitempty"`
+	// ScreenshotPath is the single screenshot of logs written before multi-image turns
+	ScreenshotPath string `json:"screenshotPath,omitempty"`
+	AudioPath      string `json:"audioPath,omitempty"`
+	// ScreenText is the OCR text of the screenshots, kept so history is searchable
+	ScreenText string `json:"screenText,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+	// Text is a typed question, e.g. a follow-up
+	Text  string `json:"text,omitempty"`
+	Reply string `json:"reply"`
+}
+
+// Images returns the turn's screenshots, including the legacy single screenshot
+func (t Turn) Images() []screen.Screenshot {
+	if len(t.Screenshots) > 0 {
+		return t.Screenshots
+	}
+	if t.ScreenshotPath != "" {
+		return []screen.Screenshot{{Path: t.ScreenshotPath}}
+	}
+	return nil
+}
+
+// Log is an append-only conversation file
+type Log struct {
+	ID   string
+	path string
+	mu   sync.Mutex
+}
+
+// Info describes a stored conversation for listing
+type Info struct {
+	ID      string
+	Started time.Time
+	Updated time.Time
+	Turns   int
+	// Preview is the first transcript (or reply) in the conversation
+	Preview string
+}
+
+// New creates a log for a fresh conversation, named after the current time
+func New() (*Log, error) {
+	if err := os.MkdirAll(Dir, FOLDER_PERMS_READALL_WRITEOWN); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+
+	id := time.Now().Format("20060102-150405")
+	return &Log{ID: id, path: logPath(id)}, nil
+}
+
+// Open returns the log of an existing conversation
+func Open(id string) (*Log, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid session id %q", id)
+	}
+
+	path := logPath(id)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("session %q not found", id)
+		}
+		return nil, err
+	}
+	return &Log{ID: id, path: path}, nil
+}
+
+// Append writes a turn to the end of the log
+func (l *Log) Append(turn Turn) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	line, err := json.Marshal(turn)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open session log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write session log: %w", err)
+	}
+	return nil
+}
+
+// Turns reads every turn in the log, in order
+func (l *Log) Turns() ([]Turn, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return readTurns(l.path)
+}
+
+// List returns all stored conversations, most recently updated first
+func List() ([]Info, error) {
+	entries, err := os.ReadDir(Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var infos []Info
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+
+		turns, err := readTurns(filepath.Join(Dir, entry.Name()))
+		if err != nil || len(turns) == 0 {
+			continue
+		}
+
+		info := Info{
+			ID:      strings.TrimSuffix(entry.Name(), ".jsonl"),
+			Started: turns[0].Time,
+			Updated: turns[len(turns)-1].Time,
+			Turns:   len(turns),
+		}
+		for _, turn := range turns {
+			if turn.Transcript != "" {
+				info.Preview = turn.Transcript
+				break
+			}
+			if turn.Text != "" {
+				info.Preview = turn.Text
+				break
+			}
+		}
+		if info.Preview == "" {
+			info.Preview = turns[0].Reply
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Updated.After(infos[j].Updated)
+	})
+	return infos, nil
+}
+
+func readTurns(path string) ([]Turn, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var turns []Turn
+	scanner := bufio.NewScanner(file)
+	// Replies can be long, allow lines up to 4MB
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var turn Turn
+		if err := json.Unmarshal(scanner.Bytes(), &turn); err != nil {
+			return nil, fmt.Errorf("corrupt session log %s: %w", path, err)
+		}
+		turns = append(turns, turn)
+	}
+	return turns, scanner.Err()
+}
+
+func logPath(id string) string {
+	return filepath.Join(Dir, id+".jsonl")
+}
@@ -0,0 +1,195 @@
+package imageproc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // support PNG
+	"os"
+)
+
+// DefaultQuality is the JPEG quality used when none is configured
+const DefaultQuality = 85
+
+// Rect is a pixel region of the captured image
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Empty reports whether the rectangle has no area
+func (r Rect) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+func (r Rect) bounds() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
+}
+
+// Options describes the preprocessing applied before upload.
+// The zero value only re-encodes the image at DefaultQuality.
+type Options struct {
+	// Crop limits the image to a region (applied first, in source pixels)
+	Crop Rect
+	// MaxDimension downscales so neither side exceeds it (0 means no limit)
+	MaxDimension int
+	// Grayscale drops color information
+	Grayscale bool
+	// Quality is the JPEG quality, 1-100
+	Quality int
+}
+
+// Result is a preprocessed image ready for upload
+type Result struct {
+	DataURI string
+	Width   int
+	Height  int
+	// Bytes is the size of the encoded JPEG, before base64
+	Bytes int
+	// Digest is the SHA-256 of the encoded JPEG, identifying the exact image sent
+	Digest string
+}
+
+// Process loads the image at path, applies opts and encodes it as a JPEG data URI
+func Process(path string, opts Options) (*Result, error) {
+	// Read the file into memory
+	imgBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode image format dynamically
+	img, _, err := image.Decode(bytes.NewReader(imgBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return Encode(img, opts)
+}
+
+// Encode applies opts to an already decoded image and encodes it as a JPEG data URI
+func Encode(img image.Image, opts Options) (*Result, error) {
+	if !opts.Crop.Empty() {
+		cropped, err := crop(img, opts.Crop)
+		if err != nil {
+			return nil, err
+		}
+		img = cropped
+	}
+
+	if opts.MaxDimension > 0 {
+		img = downscale(img, opts.MaxDimension)
+	}
+
+	if opts.Grayscale {
+		img = grayscale(img)
+	}
+
+	quality := opts.Quality
+	if quality <= 0 {
+		quality = DefaultQuality
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+
+	// Convert to base64 data URI
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+	bounds := img.Bounds()
+	return &Result{
+		DataURI: "data:image/jpeg;base64," + encoded,
+		Width:   bounds.Dx(),
+		Height:  bounds.Dy(),
+		Bytes:   buf.Len(),
+		Digest:  fmt.Sprintf("%x", sha256.Sum256(buf.Bytes())),
+	}, nil
+}
+
+// crop returns the part of img inside r, clipped to the image bounds
+func crop(img image.Image, r Rect) (image.Image, error) {
+	bounds := img.Bounds()
+	region := r.bounds().Add(bounds.Min).Intersect(bounds)
+	if region.Empty() {
+		return nil, fmt.Errorf("crop region %+v is outside the %dx%d image", r, bounds.Dx(), bounds.Dy())
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, region.Dx(), region.Dy()))
+	draw.Draw(out, out.Bounds(), img, region.Min, draw.Src)
+	return out, nil
+}
+
+// downscale shrinks img so its longest side is at most maxDim, averaging the
+// source pixels covered by each destination pixel. Smaller images are returned as is.
+func downscale(img image.Image, maxDim int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxDim && srcH <= maxDim {
+		return img
+	}
+
+	dstW, dstH := maxDim, maxDim
+	if srcW >= srcH {
+		dstH = max(1, srcH*maxDim/srcW)
+	} else {
+		dstW = max(1, srcW*maxDim/srcH)
+	}
+
+	// Work on an RGBA copy so pixel access is cheap
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+		draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := y * srcH / dstH
+		y1 := max(y0+1, (y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := x * srcW / dstW
+			x1 := max(x0+1, (x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				// sx and sy are relative to the image origin
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					i := sx * 4
+					r += uint32(row[i])
+					g += uint32(row[i+1])
+					b += uint32(row[i+2])
+					a += uint32(row[i+3])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// grayscale converts img to 8-bit luminance
+func grayscale(img image.Image) image.Image {
+	bounds := img.Bounds()
+	out := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.Set(x, y, color.GrayModel.Convert(img.At(x, y)))
+		}
+	}
+	return out
+}
@@ -1,6 +1,8 @@
 package key
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -15,15 +17,18 @@ import (
 
 const (
 	triggerKeyRawcode = 50 // Rawcode for ` (backtick) on macOS
+	cancelKeyRawcode  = 53 // Rawcode for Escape on macOS
+	profileKeyRawcode = 97 // Rawcode for F6 on macOS
 	holdThreshold     = 700 * time.Millisecond
 	maxDuration       = 20 * time.Second
 )
 
 // listener encapsulates the key state and session lifecycle.
 type listener struct {
-    session *openai.Session
-    noAudio bool
-    pretty  bool
+    session  *openai.Session
+    noAudio  bool
+    noScreen bool
+    pretty   bool
 
     mu           sync.Mutex
     running      bool        // Is a session currently running
@@ -33,21 +38,37 @@ type listener struct {
     sessionID    int64       // Unique ID for each session
     sessionCount int64       // Counter for generating session IDs
 
-    screenshotPath string
-    audioPath      string
+    screenshots []screen.Screenshot
+    screenDone  bool // Has the screenshot goroutine finished, successfully or not
+    audioPath   string
+    live        *openai.LiveTranscript // nil unless streaming transcription is on
 }
 
 // StartKeyListener launches the listener loop.
 // It waits for backtick being held, and starts a session if held long enough.
-func StartKeyListener(session *openai.Session, noAudio bool, pretty bool, wsURL, wsToken string) error {
-	l := &listener{session: session, noAudio: noAudio, pretty: pretty}
+func StartKeyListener(session *openai.Session, noAudio, noScreen, pretty bool, wsURL, wsToken string) error {
+	l := &listener{session: session, noAudio: noAudio, noScreen: noScreen, pretty: pretty}
 
-	fmt.Printf("🎧 Listening: hold backtick ≥ %.0fms to trigger\n", holdThreshold.Seconds()*1000)
+	fmt.Printf("🎧 Listening: hold backtick ≥ %.0fms to trigger, Escape cancels a running answer, F6 cycles profiles\n", holdThreshold.Seconds()*1000)
 
 	eventChan := hook.Start()
 	defer hook.End()
 
 	for ev := range eventChan {
+		if ev.Rawcode == cancelKeyRawcode {
+			if ev.Kind == hook.KeyDown && l.session.Cancel() {
+				fmt.Println("⏹️  Cancelling current request...")
+			}
+			continue
+		}
+		if ev.Rawcode == profileKeyRawcode {
+			if ev.Kind == hook.KeyDown {
+				if _, err := l.session.CycleProfile(); err != nil {
+					fmt.Printf("❌ Profile switch failed: %v\n", err)
+				}
+			}
+			continue
+		}
 		if ev.Rawcode != triggerKeyRawcode {
 			continue
 		}
@@ -102,26 +123,44 @@ func (l *listener) startSession() {
     l.running = true
     l.stopping = false  // ✅ reset for the new session
     l.sessionID = atomic.AddInt64(&l.sessionCount, 1)
+    l.screenshots = nil // don't reuse the previous session's captures
+    l.screenDone = l.noScreen
+    l.session.SetCapturing(true)
 
 	fmt.Println("▶️  Starting capture session...")
 
     // Take screenshot and wait for it to complete
-    go func() {
-        screenshotPath, err := screen.CaptureScreenshot()
-        l.mu.Lock()
-        if err != nil {
-            // Log error but continue without screenshot
-            fmt.Println("❌ Screenshot failed:", err)
-        } else {
-            l.screenshotPath = screenshotPath
-        }
-        l.mu.Unlock()
-    }()
+    if !l.noScreen {
+        go func() {
+            screenshots, err := screen.CaptureScreenshots()
+            l.mu.Lock()
+            if err != nil {
+                // Log error but continue without screenshot
+                fmt.Println("❌ Screenshot failed:", err)
+            } else {
+                l.screenshots = screenshots
+            }
+            l.screenDone = true
+            l.mu.Unlock()
+        }()
+    }
 
-    // Start audio recording in background
+    // Start audio recording in background, transcribing it in segments
+    // while the key is held when streaming is on
+    l.live = nil
     if !l.noAudio {
+        l.live = l.session.StartLiveTranscript()
+        live := l.live
         go func() {
-            if err := audio.StartRecording(); err != nil {
+            var err error
+            if live != nil {
+                err = audio.StartSegmentedRecording(live.SegmentDuration(), func(seg audio.Segment) {
+                    live.Add(seg.Index, seg.Path)
+                })
+            } else {
+                err = audio.StartRecording()
+            }
+            if err != nil {
 				fmt.Println("❌ Failed to start audio recording:", err)
             }
         }()
@@ -147,27 +186,19 @@ func (l *listener) stopSession(reason string) {
     l.stopping = true
     l.mu.Unlock()
 
-    // Wait for screenshot to be captured (with timeout)
+    // Wait for screenshot to be captured (with timeout). A failed or skipped
+    // capture finishes straight away; the turn then goes out without it.
     screenshotTimeout := time.After(10 * time.Second)
     screenshotReady := make(chan bool, 1)
     
     go func() {
-        l.mu.Lock()
-        hasScreenshot := l.screenshotPath != ""
-        l.mu.Unlock()
-        
-        if hasScreenshot {
-            screenshotReady <- true
-            return
-        }
-        
         // Wait for screenshot goroutine to complete
         for {
             l.mu.Lock()
-            hasScreenshot = l.screenshotPath != ""
+            done := l.screenDone
             l.mu.Unlock()
             
-            if hasScreenshot {
+            if done {
                 screenshotReady <- true
                 return
             }
@@ -202,8 +233,9 @@ func (l *listener) stopSession(reason string) {
     // Wait for screenshot to be ready
     screenshotSuccess := <-screenshotReady
     if !screenshotSuccess {
+        fmt.Println("⚠️  Screenshot not ready after 10s, continuing without it")
         l.mu.Lock()
-        l.screenshotPath = ""
+        l.screenshots = nil
         l.mu.Unlock()
     }
 
@@ -212,13 +244,22 @@ func (l *listener) stopSession(reason string) {
     // Mark session as not running and allow new sessions
     l.mu.Lock()
     l.running = false
+    input := openai.Input{Screenshots: l.screenshots, AudioPath: l.audioPath, Live: l.live}
     l.mu.Unlock()
 
     go func() {
-        if err := l.session.Process(l.screenshotPath, l.audioPath, l.pretty); err != nil {
-			fmt.Println("❌ Error during OpenAI processing:", err)
+        if err := l.session.Process(context.Background(), input, l.pretty); err != nil {
+            if errors.Is(err, openai.ErrCancelled) {
+                fmt.Println("⏹️  Request cancelled")
+            } else if errors.Is(err, openai.ErrQueued) {
+                fmt.Println("📥 Provider unreachable,", err)
+            } else {
+                fmt.Println("❌ Error during OpenAI processing:", err)
+            }
         }
         
+        l.session.SetCapturing(false)
+
         // Ready for a new session
         l.mu.Lock()
         l.stopping = false
@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AudioDuration returns the length of a WAV or MP3 recording. Transcription
+// is billed per second of audio, so providers report it with the transcript.
+func AudioDuration(path string) (time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		return wavDuration(data)
+	case ".mp3":
+		return mp3Duration(data)
+	default:
+		return 0, fmt.Errorf("unsupported audio format %q", filepath.Ext(path))
+	}
+}
+
+// wavDuration reads the fmt and data chunks of a RIFF/WAVE file
+func wavDuration(data []byte) (time.Duration, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0, errors.New("not a WAV file")
+	}
+
+	var byteRate uint32
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := pos + 8
+		switch {
+		case id == "fmt " && body+12 <= len(data):
+			byteRate = binary.LittleEndian.Uint32(data[body+8 : body+12])
+		case id == "data":
+			if byteRate == 0 {
+				return 0, errors.New("WAV data chunk before fmt chunk")
+			}
+			size = min(size, len(data)-body)
+			return time.Duration(float64(size) / float64(byteRate) * float64(time.Second)), nil
+		}
+		pos = body + size + size%2
+	}
+	return 0, errors.New("WAV file has no data chunk")
+}
+
+// Layer III bitrates in kbps, by MPEG version (1 or 2/2.5) and index
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// mp3Duration walks the MPEG Layer III frames, which handles the VBR files
+// ffmpeg produces for recordings
+func mp3Duration(data []byte) (time.Duration, error) {
+	pos := 0
+	// Skip an ID3v2 tag: 10-byte header with a syncsafe size
+	if len(data) >= 10 && bytes.Equal(data[0:3], []byte("ID3")) {
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		pos = 10 + size
+	}
+
+	var seconds float64
+	frames := 0
+	for pos+4 <= len(data) {
+		header := binary.BigEndian.Uint32(data[pos : pos+4])
+		if header&0xFFE00000 != 0xFFE00000 {
+			if frames > 0 {
+				break // trailing tags
+			}
+			pos++ // resync until the first frame
+			continue
+		}
+
+		version := (header >> 19) & 0x3 // 0: MPEG 2.5, 2: MPEG 2, 3: MPEG 1
+		layer := (header >> 17) & 0x3   // 1: Layer III
+		bitrateIndex := (header >> 12) & 0xF
+		rateIndex := (header >> 10) & 0x3
+		padding := int((header >> 9) & 0x1)
+		if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+			if frames > 0 {
+				break
+			}
+			pos++
+			continue
+		}
+
+		sampleRate := [3]int{44100, 48000, 32000}[rateIndex]
+		bitrate := mp3BitratesV1[bitrateIndex]
+		samples, slot := 1152, 144
+		if version != 3 {
+			sampleRate /= 2
+			if version == 0 {
+				sampleRate /= 2
+			}
+			bitrate = mp3BitratesV2[bitrateIndex]
+			samples, slot = 576, 72
+		}
+
+		seconds += float64(samples) / float64(sampleRate)
+		frames++
+		pos += slot*bitrate*1000/sampleRate + padding
+	}
+
+	if frames == 0 {
+		return 0, errors.New("no MP3 frames found")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
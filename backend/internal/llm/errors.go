@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrorClass groups provider errors by how the caller should react
+type ErrorClass int
+
+const (
+	// ClassUnknown errors are not retried
+	ClassUnknown ErrorClass = iota
+	// ClassRetryable covers rate limits, server errors and dropped connections
+	ClassRetryable
+	// ClassAuth means the API key is missing, invalid or lacks permission
+	ClassAuth
+	// ClassQuota means the account is out of credit; waiting won't help
+	ClassQuota
+	// ClassBadRequest means the request itself was rejected
+	ClassBadRequest
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassRetryable:
+		return "retryable"
+	case ClassAuth:
+		return "auth"
+	case ClassQuota:
+		return "quota"
+	case ClassBadRequest:
+		return "bad request"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is a classified provider error. Providers wrap vendor errors in it so
+// retry and reporting logic doesn't depend on any particular SDK.
+type Error struct {
+	Class      ErrorClass
+	StatusCode int
+	// RetryAfter is the server-requested wait before retrying, if any
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s error (HTTP %d): %v", e.Class, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s error: %v", e.Class, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Classify returns the class of err. Errors that weren't wrapped by a provider
+// are classified from the transport error itself.
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ClassUnknown
+	}
+
+	var llmErr *Error
+	if errors.As(err, &llmErr) {
+		return llmErr.Class
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ClassUnknown
+	}
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return ClassRetryable
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ClassRetryable
+	}
+	return ClassUnknown
+}
+
+// ClassifyHTTP classifies an HTTP error response. code is the vendor's error
+// code, used to tell a rate limit apart from an exhausted quota.
+func ClassifyHTTP(status int, code string) ErrorClass {
+	switch {
+	case status == http.StatusTooManyRequests:
+		if strings.Contains(code, "quota") {
+			return ClassQuota
+		}
+		return ClassRetryable
+	case status == http.StatusPaymentRequired:
+		return ClassQuota
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ClassAuth
+	case status == http.StatusRequestTimeout || status == http.StatusConflict || status >= 500:
+		return ClassRetryable
+	case status >= 400:
+		return ClassBadRequest
+	default:
+		return ClassUnknown
+	}
+}
+
+// ParseRetryAfter reads the retry-after-ms and Retry-After headers.
+// Retry-After may be a number of seconds or an HTTP date.
+func ParseRetryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,275 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	openai "github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+const (
+	openAIChatModel          = "gpt-4.1"
+	openAITranscriptionModel = "whisper-1"
+)
+
+func init() {
+	Register("openai", NewOpenAIProvider)
+}
+
+// OpenAIProvider implements Provider on top of the official openai-go client.
+// It also works with any OpenAI-compatible server (llama.cpp, Ollama, vLLM)
+// when a base URL is configured.
+type OpenAIProvider struct {
+	client             openai.Client
+	chatModel          string
+	transcriptionModel string
+}
+
+// NewOpenAIProvider creates an OpenAIProvider.
+// An API key is required for the hosted API but optional with a custom base URL.
+func NewOpenAIProvider(cfg Config) (Provider, error) {
+	if cfg.BaseURL == "" && cfg.APIKey == "" {
+		return nil, errors.New("OPENAI_API_KEY not set")
+	}
+
+	// Retries are handled by WithRetry so they can be classified and limited
+	opts := []option.RequestOption{option.WithMaxRetries(0)}
+	if cfg.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(cfg.BaseURL))
+	}
+	if cfg.APIKey != "" {
+		opts = append(opts, option.WithAPIKey(cfg.APIKey))
+	}
+
+	chatModel := cfg.ChatModel
+	if chatModel == "" {
+		chatModel = openAIChatModel
+	}
+	transcriptionModel := cfg.TranscriptionModel
+	if transcriptionModel == "" {
+		transcriptionModel = openAITranscriptionModel
+	}
+
+	return &OpenAIProvider{
+		client:             openai.NewClient(opts...),
+		chatModel:          chatModel,
+		transcriptionModel: transcriptionModel,
+	}, nil
+}
+
+// Name implements Provider
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+// StreamChat implements Provider
+func (p *OpenAIProvider) StreamChat(ctx context.Context, req ChatRequest, onDelta DeltaFunc) (*ChatResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.chatModel
+	}
+
+	params := openai.ChatCompletionNewParams{
+		Messages: toOpenAIMessages(req.Messages, req.ImageDetail),
+		Model:    model,
+		// Ask for a final usage chunk so spend can be tracked
+		StreamOptions: openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)},
+	}
+	if req.Temperature != nil {
+		params.Temperature = openai.Float(*req.Temperature)
+	}
+	if req.MaxTokens > 0 {
+		params.MaxCompletionTokens = openai.Int(req.MaxTokens)
+	}
+	for _, tool := range req.Tools {
+		params.Tools = append(params.Tools, openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+			Name:        tool.Name,
+			Description: openai.String(tool.Description),
+			Parameters:  openai.FunctionParameters(tool.Parameters),
+		}))
+	}
+
+	if rs := req.ResponseSchema; rs != nil {
+		schema := shared.ResponseFormatJSONSchemaJSONSchemaParam{
+			Name:   rs.Name,
+			Schema: rs.Schema,
+			Strict: openai.Bool(rs.Strict),
+		}
+		if rs.Description != "" {
+			schema.Description = openai.String(rs.Description)
+		}
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{JSONSchema: schema},
+		}
+	}
+
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	var fullContent string
+	var toolCalls []ToolCall
+	resp := &ChatResponse{Model: model}
+	for stream.Next() {
+		chunk := stream.Current()
+		if chunk.Model != "" {
+			resp.Model = chunk.Model
+		}
+		// Only the last chunk carries usage
+		if chunk.Usage.PromptTokens > 0 || chunk.Usage.CompletionTokens > 0 {
+			resp.Usage = Usage{
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+				CachedTokens:     chunk.Usage.PromptTokensDetails.CachedTokens,
+			}
+		}
+		if len(chunk.Choices) > 0 {
+			delta := chunk.Choices[0].Delta.Content
+			if delta != "" {
+				if onDelta != nil {
+					onDelta(delta)
+				}
+				fullContent += delta
+			}
+
+			// Tool calls arrive in fragments keyed by index
+			for _, tc := range chunk.Choices[0].Delta.ToolCalls {
+				for int(tc.Index) >= len(toolCalls) {
+					toolCalls = append(toolCalls, ToolCall{})
+				}
+				call := &toolCalls[tc.Index]
+				if tc.ID != "" {
+					call.ID = tc.ID
+				}
+				call.Name += tc.Function.Name
+				call.Arguments += tc.Function.Arguments
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, wrapOpenAIError(err)
+	}
+
+	resp.Content = fullContent
+	resp.ToolCalls = toolCalls
+	return resp, nil
+}
+
+// Transcribe implements Provider using Whisper
+func (p *OpenAIProvider) Transcribe(ctx context.Context, audioPath string, opts TranscriptionOptions) (*Transcription, error) {
+	if audioPath == "" {
+		return &Transcription{}, nil
+	}
+
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	model := opts.Model
+	if model == "" {
+		model = p.transcriptionModel
+	}
+	params := openai.AudioTranscriptionNewParams{
+		File:  file,
+		Model: model,
+	}
+	if opts.Language != "" {
+		params.Language = openai.String(opts.Language)
+	}
+	if opts.Prompt != "" {
+		params.Prompt = openai.String(opts.Prompt)
+	}
+	if opts.Temperature != nil {
+		params.Temperature = openai.Float(*opts.Temperature)
+	}
+	resp, err := p.client.Audio.Transcriptions.New(ctx, params)
+	if err != nil {
+		return nil, wrapOpenAIError(err)
+	}
+
+	duration, err := AudioDuration(audioPath)
+	if err != nil {
+		fmt.Printf("Warning: failed to measure %s: %v\n", audioPath, err)
+	}
+	return &Transcription{Text: resp.Text, Model: model, Duration: duration}, nil
+}
+
+// wrapOpenAIError classifies openai-go API errors; other errors pass through
+func wrapOpenAIError(err error) error {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	wrapped := &Error{
+		Class:      ClassifyHTTP(apiErr.StatusCode, apiErr.Code),
+		StatusCode: apiErr.StatusCode,
+		Err:        err,
+	}
+	if apiErr.Response != nil {
+		wrapped.RetryAfter = ParseRetryAfter(apiErr.Response.Header)
+	}
+	return wrapped
+}
+
+// toOpenAIMessages converts neutral messages to openai-go message params
+func toOpenAIMessages(messages []Message, imageDetail string) []openai.ChatCompletionMessageParamUnion {
+	if imageDetail == "" {
+		imageDetail = "auto"
+	}
+
+	out := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case RoleSystem:
+			out = append(out, openai.SystemMessage(msg.Content))
+		case RoleAssistant:
+			if len(msg.ToolCalls) == 0 {
+				out = append(out, openai.AssistantMessage(msg.Content))
+				continue
+			}
+			assistant := openai.ChatCompletionAssistantMessageParam{}
+			if msg.Content != "" {
+				assistant.Content.OfString = openai.String(msg.Content)
+			}
+			for _, call := range msg.ToolCalls {
+				assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+					OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+						ID: call.ID,
+						Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+							Name:      call.Name,
+							Arguments: call.Arguments,
+						},
+					},
+				})
+			}
+			out = append(out, openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant})
+		case RoleTool:
+			out = append(out, openai.ToolMessage(msg.Content, msg.ToolCallID))
+		case RoleUser:
+			if len(msg.Parts) == 0 {
+				out = append(out, openai.UserMessage(msg.Content))
+				continue
+			}
+			parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(msg.Parts))
+			for _, part := range msg.Parts {
+				switch part.Type {
+				case PartImage:
+					parts = append(parts, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+						URL:    part.ImageURL,
+						Detail: imageDetail,
+					}))
+				default:
+					parts = append(parts, openai.TextContentPart(part.Text))
+				}
+			}
+			out = append(out, openai.UserMessage(parts))
+		}
+	}
+	return out
+}
@@ -0,0 +1,240 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Role identifies the author of a message in a conversation
+type Role string
+
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+)
+
+// PartType identifies the kind of content carried by a Part
+type PartType string
+
+const (
+	PartText  PartType = "text"
+	PartImage PartType = "image"
+)
+
+// Part is a single piece of user content, either text or an image
+type Part struct {
+	Type PartType `json:"type"`
+	Text string   `json:"text,omitempty"`
+	// ImageURL is a data URI (or remote URL) for image parts
+	ImageURL string `json:"imageUrl,omitempty"`
+}
+
+// TextPart returns a text content part
+func TextPart(text string) Part {
+	return Part{Type: PartText, Text: text}
+}
+
+// ImagePart returns an image content part for the given data URI
+func ImagePart(url string) Part {
+	return Part{Type: PartImage, ImageURL: url}
+}
+
+// Message is a vendor-neutral chat message.
+// System, assistant and tool messages use Content; user messages use Parts.
+type Message struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content,omitempty"`
+	Parts   []Part `json:"parts,omitempty"`
+	// ToolCalls are the tools requested by an assistant message
+	ToolCalls []ToolCall `json:"toolCalls,omitempty"`
+	// ToolCallID links a tool message to the call it answers
+	ToolCallID string `json:"toolCallId,omitempty"`
+}
+
+// ToolDefinition describes a tool the model may call
+type ToolDefinition struct {
+	Name        string
+	Description string
+	// Parameters is the JSON schema of the tool's arguments
+	Parameters map[string]any
+}
+
+// ResponseSchema asks the model to answer with JSON matching a schema
+type ResponseSchema struct {
+	// Name identifies the format (letters, digits, underscores and dashes)
+	Name        string
+	Description string
+	// Schema is the JSON schema the answer must follow
+	Schema map[string]any
+	// Strict enables the provider's strict schema adherence, where supported
+	Strict bool
+}
+
+// ToolCall is a single tool invocation requested by the model
+type ToolCall struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Arguments is the raw JSON argument object
+	Arguments string `json:"arguments"`
+}
+
+// SystemMessage returns a system message with the given text
+func SystemMessage(content string) Message {
+	return Message{Role: RoleSystem, Content: content}
+}
+
+// UserMessage returns a user message made of the given parts
+func UserMessage(parts ...Part) Message {
+	return Message{Role: RoleUser, Parts: parts}
+}
+
+// AssistantMessage returns an assistant message with the given text
+func AssistantMessage(content string) Message {
+	return Message{Role: RoleAssistant, Content: content}
+}
+
+// AssistantToolCallMessage returns an assistant message that requests tool calls
+func AssistantToolCallMessage(content string, calls []ToolCall) Message {
+	return Message{Role: RoleAssistant, Content: content, ToolCalls: calls}
+}
+
+// ToolMessage returns the result of a tool call
+func ToolMessage(callID, content string) Message {
+	return Message{Role: RoleTool, Content: content, ToolCallID: callID}
+}
+
+// ChatRequest is a single streaming chat completion request
+type ChatRequest struct {
+	// Model overrides the provider's default chat model when set
+	Model    string
+	Messages []Message
+	// Temperature is left to the provider default when nil
+	Temperature *float64
+	// MaxTokens caps the completion length when positive
+	MaxTokens int64
+	// ImageDetail is the vision detail level for image parts ("auto", "low", "high")
+	ImageDetail string
+	// Tools the model may call instead of answering directly
+	Tools []ToolDefinition
+	// ResponseSchema switches the answer to structured JSON when set
+	ResponseSchema *ResponseSchema
+}
+
+// ChatResponse is the result of a completed chat stream
+type ChatResponse struct {
+	Content string
+	// ToolCalls is set when the model stopped to call tools
+	ToolCalls []ToolCall
+	// Model is the model that actually served the request
+	Model string
+	// Usage is the token count reported by the provider, if any
+	Usage Usage
+}
+
+// Usage is the token count of a single chat request
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+	// CachedTokens is the part of PromptTokens served from the provider's prompt cache
+	CachedTokens int64
+}
+
+// Transcription is the result of transcribing an audio file
+type Transcription struct {
+	Text string
+	// Model is the transcription model used
+	Model string
+	// Duration is the length of the audio, which transcription is billed by
+	Duration time.Duration
+}
+
+// TranscriptionOptions tune a single transcription. Empty fields use the
+// provider defaults.
+type TranscriptionOptions struct {
+	// Model overrides the provider's transcription model
+	Model string
+	// Language is the ISO-639-1 code of the speech, e.g. "en"; empty auto-detects
+	Language string
+	// Prompt hints names and jargon the model should expect
+	Prompt string
+	// Temperature is a pointer so an explicit 0 can be told apart from unset
+	Temperature *float64
+}
+
+// DeltaFunc receives each content delta as it streams in
+type DeltaFunc func(delta string)
+
+// Provider is an LLM vendor capable of streaming chat and transcribing audio
+type Provider interface {
+	// Name returns the registered provider name
+	Name() string
+	// StreamChat streams a chat completion, calling onDelta for every content
+	// delta. onDelta may be nil when only the final response is needed.
+	StreamChat(ctx context.Context, req ChatRequest, onDelta DeltaFunc) (*ChatResponse, error)
+	// Transcribe converts the audio file at audioPath to text
+	Transcribe(ctx context.Context, audioPath string, opts TranscriptionOptions) (*Transcription, error)
+}
+
+// Config holds the connection settings handed to a provider factory.
+// Empty fields fall back to the provider's own defaults.
+type Config struct {
+	// BaseURL targets a self-hosted, API-compatible server instead of the vendor API
+	BaseURL string
+	// APIKey is optional when BaseURL points at a server that doesn't need one
+	APIKey             string
+	ChatModel          string
+	TranscriptionModel string
+}
+
+// Factory creates a Provider from its configuration
+type Factory func(cfg Config) (Provider, error)
+
+// DefaultProvider is used when no provider is configured
+const DefaultProvider = "openai"
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a provider available by name. It is meant to be called from init.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(name)] = factory
+}
+
+// New creates the provider registered under name (DefaultProvider if empty)
+func New(name string, cfg Config) (Provider, error) {
+	if name == "" {
+		name = DefaultProvider
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(name)]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Names returns the registered provider names in sorted order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
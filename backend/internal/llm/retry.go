@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how failed provider calls are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries, including the first (1 disables retries)
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles on every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff
+	MaxDelay time.Duration
+	// MaxRetryAfter is the longest server-requested wait we are willing to honour
+	MaxRetryAfter time.Duration
+}
+
+// retryProvider decorates a Provider with retries
+type retryProvider struct {
+	Provider
+	policy RetryPolicy
+}
+
+// WithRetry wraps p so that retryable errors are retried with exponential
+// backoff and jitter. Chat streams are only retried until the first delta has
+// been passed to onDelta, so callers never see duplicated output. Callers that
+// don't show deltas should pass a nil onDelta to keep retrying mid-stream.
+func WithRetry(p Provider, policy RetryPolicy) Provider {
+	if policy.MaxAttempts <= 1 {
+		return p
+	}
+	return &retryProvider{Provider: p, policy: policy}
+}
+
+// StreamChat implements Provider
+func (r *retryProvider) StreamChat(ctx context.Context, req ChatRequest, onDelta DeltaFunc) (*ChatResponse, error) {
+	// Without a callback nothing reaches the caller before the call returns
+	forwarded := false
+	forward := onDelta
+	if onDelta != nil {
+		forward = func(delta string) {
+			forwarded = true
+			onDelta(delta)
+		}
+	}
+
+	var resp *ChatResponse
+	err := r.do(ctx, "chat", func() error {
+		var err error
+		resp, err = r.Provider.StreamChat(ctx, req, forward)
+		if err != nil && forwarded {
+			// Output already reached the writer, a retry would duplicate it
+			return permanent{err}
+		}
+		return err
+	})
+	return resp, err
+}
+
+// Transcribe implements Provider
+func (r *retryProvider) Transcribe(ctx context.Context, audioPath string, opts TranscriptionOptions) (*Transcription, error) {
+	var result *Transcription
+	err := r.do(ctx, "transcription", func() error {
+		var err error
+		result, err = r.Provider.Transcribe(ctx, audioPath, opts)
+		return err
+	})
+	return result, err
+}
+
+// do runs call until it succeeds, fails with a non-retryable error, or runs out of attempts
+func (r *retryProvider) do(ctx context.Context, what string, call func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call()
+		if err == nil {
+			return nil
+		}
+
+		var perm permanent
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if Classify(err) != ClassRetryable || attempt >= r.policy.MaxAttempts {
+			return err
+		}
+
+		wait := r.backoff(attempt)
+		var llmErr *Error
+		if errors.As(err, &llmErr) && llmErr.RetryAfter > 0 {
+			if r.policy.MaxRetryAfter > 0 && llmErr.RetryAfter > r.policy.MaxRetryAfter {
+				return err
+			}
+			wait = max(wait, llmErr.RetryAfter)
+		}
+
+		fmt.Printf("⚠️  %s attempt %d/%d failed: %v (retrying in %v)\n",
+			what, attempt, r.policy.MaxAttempts, err, wait.Round(time.Millisecond))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns an exponentially growing delay with "equal jitter":
+// half of the step is fixed and the other half random
+func (r *retryProvider) backoff(attempt int) time.Duration {
+	step := r.policy.BaseDelay << (attempt - 1)
+	if step <= 0 || (r.policy.MaxDelay > 0 && step > r.policy.MaxDelay) {
+		step = r.policy.MaxDelay
+	}
+	if step <= 0 {
+		return 0
+	}
+	half := step / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// permanent marks an error that must not be retried regardless of its class
+type permanent struct {
+	err error
+}
+
+func (p permanent) Error() string { return p.err.Error() }
+func (p permanent) Unwrap() error { return p.err }
@@ -0,0 +1,300 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// scriptedServer answers /chat/completions with the n-th handler for the
+// n-th request, repeating the last one, and counts the requests
+type scriptedServer struct {
+	*httptest.Server
+	calls atomic.Int32
+}
+
+func newScriptedServer(t *testing.T, script ...http.HandlerFunc) *scriptedServer {
+	t.Helper()
+	s := &scriptedServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(s.calls.Add(1))
+		script[min(n, len(script))-1](w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// fail replies with an OpenAI-style error body
+func fail(status int, code string, header map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"error":{"message":"scripted failure","type":"test","code":%q}}`, code)
+	}
+}
+
+func chunk(w http.ResponseWriter, content string) {
+	fmt.Fprintf(w, "data: {\"id\":\"x\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"fake\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+	w.(http.Flusher).Flush()
+}
+
+// answer streams a complete answer
+func answer(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		chunk(w, content)
+		fmt.Fprint(w, "data: {\"id\":\"x\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"fake\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}
+}
+
+// dropAfterDelta streams one delta and then cuts the connection
+func dropAfterDelta(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	chunk(w, "partial")
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+var testPolicy = RetryPolicy{
+	MaxAttempts:   3,
+	BaseDelay:     time.Millisecond,
+	MaxDelay:      5 * time.Millisecond,
+	MaxRetryAfter: 2 * time.Second,
+}
+
+func newTestProvider(t *testing.T, url string) Provider {
+	t.Helper()
+	p, err := NewOpenAIProvider(Config{BaseURL: url, APIKey: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return WithRetry(p, testPolicy)
+}
+
+func chat(p Provider) (string, error) {
+	var deltas string
+	resp, err := p.StreamChat(context.Background(), ChatRequest{
+		Messages: []Message{UserMessage(TextPart("hi"))},
+	}, func(delta string) { deltas += delta })
+	if err != nil {
+		return deltas, err
+	}
+	return resp.Content, nil
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	srv := newScriptedServer(t,
+		fail(http.StatusTooManyRequests, "rate_limit_exceeded", map[string]string{"Retry-After-Ms": "300"}),
+		answer("ok"),
+	)
+
+	start := time.Now()
+	content, err := chat(newTestProvider(t, srv.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "ok" {
+		t.Errorf("content = %q, want %q", content, "ok")
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("retried after %v, want at least the 300ms Retry-After", elapsed)
+	}
+}
+
+func TestRetryGivesUpOnLongRetryAfter(t *testing.T) {
+	srv := newScriptedServer(t,
+		fail(http.StatusTooManyRequests, "rate_limit_exceeded", map[string]string{"Retry-After": "60"}),
+		answer("ok"),
+	)
+
+	_, err := chat(newTestProvider(t, srv.URL))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := srv.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+func TestRetryServerErrorsUpToLimit(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			srv := newScriptedServer(t, fail(status, "", nil))
+
+			_, err := chat(newTestProvider(t, srv.URL))
+			if Classify(err) != ClassRetryable {
+				t.Errorf("class = %v, want retryable (err: %v)", Classify(err), err)
+			}
+			if got := srv.calls.Load(); got != int32(testPolicy.MaxAttempts) {
+				t.Errorf("calls = %d, want %d", got, testPolicy.MaxAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryRecoversFromServerError(t *testing.T) {
+	srv := newScriptedServer(t, fail(http.StatusInternalServerError, "", nil), answer("ok"))
+
+	content, err := chat(newTestProvider(t, srv.URL))
+	if err != nil || content != "ok" {
+		t.Fatalf("got %q, %v; want %q", content, err, "ok")
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestNoRetryOnPermanentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		class  ErrorClass
+	}{
+		{"unauthorized", http.StatusUnauthorized, "invalid_api_key", ClassAuth},
+		{"forbidden", http.StatusForbidden, "", ClassAuth},
+		{"payment required", http.StatusPaymentRequired, "", ClassQuota},
+		{"quota exhausted", http.StatusTooManyRequests, "insufficient_quota", ClassQuota},
+		{"bad request", http.StatusBadRequest, "invalid_request_error", ClassBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newScriptedServer(t, fail(tt.status, tt.code, nil), answer("ok"))
+
+			_, err := chat(newTestProvider(t, srv.URL))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := Classify(err); got != tt.class {
+				t.Errorf("class = %v, want %v", got, tt.class)
+			}
+			if got := srv.calls.Load(); got != 1 {
+				t.Errorf("calls = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestNoRetryAfterFirstDelta(t *testing.T) {
+	srv := newScriptedServer(t, dropAfterDelta, answer("ok"))
+
+	deltas, err := chat(newTestProvider(t, srv.URL))
+	if err == nil {
+		t.Fatal("expected the dropped stream to fail")
+	}
+	if got := srv.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+	if deltas != "partial" {
+		t.Errorf("deltas = %q, want %q without duplicates", deltas, "partial")
+	}
+}
+
+func TestRetryMidStreamWithoutDeltaCallback(t *testing.T) {
+	srv := newScriptedServer(t, dropAfterDelta, answer("ok"))
+
+	// Structured answers are held back until complete, so nothing was shown yet
+	resp, err := newTestProvider(t, srv.URL).StreamChat(context.Background(), ChatRequest{
+		Messages: []Message{UserMessage(TextPart("hi"))},
+	}, nil)
+	if err != nil || resp.Content != "ok" {
+		t.Fatalf("got %+v, %v; want %q", resp, err, "ok")
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestRetryBeforeFirstDelta(t *testing.T) {
+	dropBeforeDelta := func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}
+	srv := newScriptedServer(t, dropBeforeDelta, answer("ok"))
+
+	content, err := chat(newTestProvider(t, srv.URL))
+	if err != nil || content != "ok" {
+		t.Fatalf("got %q, %v; want %q", content, err, "ok")
+	}
+	if got := srv.calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestClassifyHTTP(t *testing.T) {
+	tests := []struct {
+		status int
+		code   string
+		want   ErrorClass
+	}{
+		{429, "rate_limit_exceeded", ClassRetryable},
+		{429, "insufficient_quota", ClassQuota},
+		{402, "", ClassQuota},
+		{401, "", ClassAuth},
+		{403, "", ClassAuth},
+		{408, "", ClassRetryable},
+		{409, "", ClassRetryable},
+		{500, "", ClassRetryable},
+		{503, "", ClassRetryable},
+		{400, "", ClassBadRequest},
+		{404, "", ClassBadRequest},
+		{200, "", ClassUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyHTTP(tt.status, tt.code); got != tt.want {
+			t.Errorf("ClassifyHTTP(%d, %q) = %v, want %v", tt.status, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header http.Header
+		want   time.Duration
+	}{
+		{nil, 0},
+		{http.Header{"Retry-After": {"2"}}, 2 * time.Second},
+		{http.Header{"Retry-After-Ms": {"250"}}, 250 * time.Millisecond},
+		{http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"2"}}, 250 * time.Millisecond},
+		{http.Header{"Retry-After": {"soon"}}, 0},
+	}
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.header); got != tt.want {
+			t.Errorf("ParseRetryAfter(%v) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	srv := newScriptedServer(t,
+		fail(http.StatusTooManyRequests, "rate_limit_exceeded", map[string]string{"Retry-After": "1"}),
+		answer("ok"),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := newTestProvider(t, srv.URL).StreamChat(ctx, ChatRequest{
+		Messages: []Message{UserMessage(TextPart("hi"))},
+	}, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context's error", err)
+	}
+	if got := srv.calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"encoding/base64"
+	"image"
+	_ "image/jpeg" // support JPEG
+	_ "image/png"  // support PNG
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// messageOverheadTokens approximates the per-message framing cost
+	messageOverheadTokens = 4
+	// lowDetailImageTokens is the flat cost of a low detail image
+	lowDetailImageTokens = 85
+	// tileTokens is the cost of each 512px tile in a high detail image
+	tileTokens = 170
+)
+
+// EstimateTextTokens approximates the token count of text (~4 characters per token)
+func EstimateTextTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// EstimateImageTokens approximates the vision token cost of an image data URI.
+// It follows OpenAI's tiling rules and only decodes the image header.
+func EstimateImageTokens(dataURI, detail string) int {
+	if detail == "low" {
+		return lowDetailImageTokens
+	}
+
+	width, height, ok := imageSize(dataURI)
+	if !ok {
+		// Unknown size: assume a typical 1080p screenshot
+		width, height = 1920, 1080
+	}
+	return EstimateImageTokensForSize(width, height, detail)
+}
+
+// EstimateImageTokensForSize approximates the vision token cost of a width x height image
+func EstimateImageTokensForSize(width, height int, detail string) int {
+	if detail == "low" || width <= 0 || height <= 0 {
+		return lowDetailImageTokens
+	}
+
+	w, h := float64(width), float64(height)
+
+	// Fit within 2048x2048
+	if scale := 2048 / math.Max(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+	// Shortest side scaled down to 768
+	if scale := 768 / math.Min(w, h); scale < 1 {
+		w, h = w*scale, h*scale
+	}
+
+	tiles := int(math.Ceil(w/512) * math.Ceil(h/512))
+	return tiles*tileTokens + lowDetailImageTokens
+}
+
+// EstimateMessageTokens approximates the token count of a single message
+func EstimateMessageTokens(msg Message, imageDetail string) int {
+	tokens := messageOverheadTokens + EstimateTextTokens(msg.Content)
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case PartImage:
+			tokens += EstimateImageTokens(part.ImageURL, imageDetail)
+		default:
+			tokens += EstimateTextTokens(part.Text)
+		}
+	}
+	return tokens
+}
+
+// EstimateTokens approximates the prompt token count of a conversation
+func EstimateTokens(messages []Message, imageDetail string) int {
+	total := 0
+	for _, msg := range messages {
+		total += EstimateMessageTokens(msg, imageDetail)
+	}
+	return total
+}
+
+// imageSize reads the dimensions of a base64 data URI without decoding the pixels
+func imageSize(dataURI string) (int, int, bool) {
+	idx := strings.Index(dataURI, ";base64,")
+	if !strings.HasPrefix(dataURI, "data:") || idx < 0 {
+		return 0, 0, false
+	}
+
+	payload := dataURI[idx+len(";base64,"):]
+	cfg, _, err := image.DecodeConfig(base64.NewDecoder(base64.StdEncoding, strings.NewReader(payload)))
+	if err != nil {
+		return 0, 0, false
+	}
+	return cfg.Width, cfg.Height, true
+}
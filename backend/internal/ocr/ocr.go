@@ -0,0 +1,39 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// Engine extracts the text shown in a screenshot
+type Engine interface {
+	// Name identifies the engine in logs
+	Name() string
+	// Recognize returns the text found in the image at path
+	Recognize(ctx context.Context, imagePath string) (string, error)
+}
+
+// New returns the engine selected in cfg
+func New(cfg config.OCRConfig) (Engine, error) {
+	switch cfg.Engine {
+	case config.OCREngineNone:
+		return Noop{}, nil
+	case config.OCREngineTesseract:
+		return NewTesseract(cfg)
+	default:
+		return nil, fmt.Errorf("unknown OCR engine %q", cfg.Engine)
+	}
+}
+
+// Noop is the default engine; it never finds any text
+type Noop struct{}
+
+// Name implements Engine
+func (Noop) Name() string { return config.OCREngineNone }
+
+// Recognize implements Engine
+func (Noop) Recognize(ctx context.Context, imagePath string) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,55 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// Tesseract runs the tesseract command line tool
+type Tesseract struct {
+	command   string
+	languages string
+	timeout   time.Duration
+}
+
+// NewTesseract checks that the tesseract executable is available
+func NewTesseract(cfg config.OCRConfig) (*Tesseract, error) {
+	command, err := exec.LookPath(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("tesseract not found (install it with `brew install tesseract`): %w", err)
+	}
+	return &Tesseract{
+		command:   command,
+		languages: cfg.Languages,
+		timeout:   time.Duration(cfg.TimeoutSec) * time.Second,
+	}, nil
+}
+
+// Name implements Engine
+func (t *Tesseract) Name() string { return config.OCREngineTesseract }
+
+// Recognize implements Engine
+func (t *Tesseract) Recognize(ctx context.Context, imagePath string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	// "stdout" as the output base makes tesseract print instead of writing a file
+	cmd := exec.CommandContext(ctx, t.command, imagePath, "stdout", "-l", t.languages)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("tesseract timed out after %v", t.timeout)
+		}
+		return "", fmt.Errorf("tesseract failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
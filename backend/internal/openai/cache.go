@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/cache"
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
+)
+
+// SetCache makes the session replay stored answers for repeated captures
+func (s *Session) SetCache(c *cache.Cache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = c
+}
+
+// cacheKey combines the digests of the preprocessed screenshots, the screen
+// text, the transcript, the typed text, the earlier conversation, the model and
+// the prompt. It returns "" when caching is off or there is no capture to key
+// on; typed text alone refers to the conversation, so it is never cached.
+func (s *Session) cacheKey(rules *config.Rules, history []llm.Message, imageDigests, screenTexts []string, transcript, text string) string {
+	s.mu.Lock()
+	c := s.cache
+	s.mu.Unlock()
+
+	if c == nil || (len(imageDigests) == 0 && len(screenTexts) == 0 && transcript == "") {
+		return ""
+	}
+
+	// The answer format is part of the prompt
+	var schema []byte
+	if rules.Structured != nil {
+		schema, _ = json.Marshal(rules.Structured)
+	}
+
+	return cache.Key(
+		strings.Join(imageDigests, ","),
+		strings.Join(screenTexts, "\n\n"),
+		transcript,
+		text,
+		historyDigest(history),
+		s.provider.Name(),
+		rules.Chat.Model,
+		rules.SystemPrompt,
+		rules.WhatDoYouNeedHelpWith,
+		string(schema),
+	)
+}
+
+// historyDigest identifies the conversation an answer was given in, so the
+// same capture in a different conversation isn't answered from the cache. The
+// system prompt is left out since it is keyed separately; summary notes count.
+func historyDigest(history []llm.Message) string {
+	var parts []string
+	for _, msg := range history {
+		if msg.Role == llm.RoleSystem && !isSummaryNote(msg) {
+			continue
+		}
+		data, err := json.Marshal(msg)
+		if err != nil {
+			continue
+		}
+		parts = append(parts, string(data))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return cache.Key(parts...)
+}
+
+// replayCached sends a stored answer for key to the writer, flagged as cached
+func (s *Session) replayCached(id int64, rules *config.Rules, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	entry, ok := s.cache.Get(key)
+	if !ok {
+		return "", false
+	}
+
+	fmt.Printf("♻️  Cache hit, replaying answer from %s\n", entry.Created.Format("2006-01-02 15:04"))
+	s.emit(stream.Event{Type: stream.EventCached, RequestID: id})
+	if rules.Structured != nil {
+		s.deliverStructured(id, rules.Structured, entry.Answer)
+	} else {
+		s.emit(stream.Event{Type: stream.EventDelta, RequestID: id, Text: entry.Answer})
+	}
+	return entry.Answer, true
+}
+
+// storeCached saves a fresh answer under key
+func (s *Session) storeCached(rules *config.Rules, key, answer string) {
+	if key == "" || answer == "" {
+		return
+	}
+	err := s.cache.Put(cache.Entry{
+		Key:     key,
+		Model:   rules.Chat.Model,
+		Profile: rules.ActiveProfile,
+		Answer:  answer,
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to cache answer: %v\n", err)
+	}
+}
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// ErrCancelled is returned by Process when the request was cancelled by the user
+var ErrCancelled = errors.New("request cancelled")
+
+// Cancel stops every in-flight request. It returns false if nothing was running.
+func (s *Session) Cancel() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.inflight) == 0 {
+		return false
+	}
+	for _, cancel := range s.inflight {
+		cancel()
+	}
+	return true
+}
+
+// newRequestID returns the ID of the next request
+func (s *Session) newRequestID() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestCount++
+	return s.requestCount
+}
+
+// track registers a request's cancel func
+func (s *Session) track(id int64, cancel context.CancelFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.inflight == nil {
+		s.inflight = map[int64]context.CancelFunc{}
+	}
+	s.inflight[id] = cancel
+}
+
+// untrack removes a finished request
+func (s *Session) untrack(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inflight, id)
+}
+
+// cancelled reports whether err came from a cancelled context, and if so
+// returns ErrCancelled wrapped with the stage that was interrupted
+func cancelled(ctx context.Context, stage string) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("%s: %w", stage, ErrCancelled)
+	}
+	return nil
+}
+
+// finishCancelled records the turn with its partial reply so the conversation
+// keeps alternating user/assistant turns. Process tells the writer.
+func (s *Session) finishCancelled(rules *config.Rules, turn []llm.Message, partial string) {
+	s.commitTurn(rules, append(turn, llm.AssistantMessage(partial+"\n\n[cancelled by the user]"))...)
+}
@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
+)
+
+// emit sends an event to the writer. Writer failures are logged and never
+// fail the request.
+func (s *Session) emit(ev stream.Event) {
+	if s.writer == nil {
+		return
+	}
+	if err := s.writer.WriteEvent(ev); err != nil {
+		fmt.Printf("Warning: failed to write %s event to stream: %v\n", ev.Type, err)
+	}
+}
+
+// emitFailed closes a request that returned err with a cancelled or error event
+func (s *Session) emitFailed(id int64, err error) {
+	if errors.Is(err, ErrCancelled) {
+		s.emit(stream.Event{Type: stream.EventCancelled, RequestID: id})
+		return
+	}
+	s.emit(stream.Event{Type: stream.EventError, RequestID: id, Text: err.Error()})
+}
+
+// emitUsage reports the tokens a request used, if the provider counted any
+func (s *Session) emitUsage(id int64, usage llm.Usage) {
+	if usage == (llm.Usage{}) {
+		return
+	}
+	s.emit(stream.Event{
+		Type:      stream.EventUsage,
+		RequestID: id,
+		Usage: &stream.Usage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			CachedTokens:     usage.CachedTokens,
+		},
+	})
+}
@@ -0,0 +1,12 @@
+package openai
+
+import (
+	"context"
+	"strings"
+)
+
+// FollowUp sends a typed question about the conversation so far as a
+// text-only turn, without a new capture
+func (s *Session) FollowUp(ctx context.Context, text string, pretty bool) error {
+	return s.Process(ctx, Input{Text: strings.TrimSpace(text)}, pretty)
+}
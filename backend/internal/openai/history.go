@@ -0,0 +1,212 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+const (
+	// summaryNotePrefix marks the system note that replaces summarized turns
+	summaryNotePrefix = "Summary of the earlier conversation:\n\n"
+	// omittedImageText replaces screenshots dropped from older turns
+	omittedImageText = "[earlier screenshot omitted]"
+	// summaryMaxTokens caps the length of the generated summary
+	summaryMaxTokens = 1024
+)
+
+const summarizePrompt = `Summarize the following conversation between a user and their assistant.
+Keep the facts, decisions, code, and open questions the assistant will need to continue helping.
+Write a compact note, not a transcript. Do not add commentary.`
+
+// commitTurn appends a finished turn to the conversation in one step, so
+// overlapping requests don't interleave their messages
+func (s *Session) commitTurn(rules *config.Rules, turn ...llm.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, turn...)
+	trimImages(s.messages, rules.History.ImagesKept())
+}
+
+// trimImages replaces screenshots in all but the most recent keep user turns
+// with a short placeholder, keeping the text of those turns. The last message
+// is the one being sent and always keeps its images.
+func trimImages(messages []llm.Message, keep int) {
+	seen := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Role != llm.RoleUser || !hasImage(msg) {
+			continue
+		}
+		seen++
+		if seen <= keep || i == len(messages)-1 {
+			continue
+		}
+
+		// Build a new parts slice so snapshots handed to the provider are untouched
+		parts := make([]llm.Part, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			if part.Type == llm.PartImage {
+				part = llm.TextPart(omittedImageText)
+			}
+			parts = append(parts, part)
+		}
+		messages[i] = llm.Message{Role: msg.Role, Content: msg.Content, Parts: parts}
+	}
+}
+
+// compactHistory summarizes older turns into a system note once the estimated
+// prompt size, as sent with rules, exceeds the configured budget. If
+// summarization fails the older turns are dropped instead so the session
+// stays within budget; if it is cancelled they are kept.
+func (s *Session) compactHistory(ctx context.Context, rules *config.Rules) {
+	s.compactMu.Lock()
+	defer s.compactMu.Unlock()
+
+	s.mu.Lock()
+	tokens := llm.EstimateTokens(s.messages, rules.Chat.ImageDetail)
+	if tokens <= rules.History.MaxTokens {
+		s.mu.Unlock()
+		return
+	}
+	start, cut := s.compactionRange(rules.History.RecentTurnsKept())
+	if cut <= start {
+		s.mu.Unlock()
+		return
+	}
+	older := append([]llm.Message(nil), s.messages[:cut]...)
+	s.mu.Unlock()
+
+	fmt.Printf("🗜️  History at ~%d tokens (budget %d), summarizing %d older messages\n",
+		tokens, rules.History.MaxTokens, cut-start)
+
+	summary, err := s.summarize(ctx, rules, older)
+
+	// Only compaction removes messages, and it is serialized by compactMu,
+	// so indices below cut are still valid even if new turns were appended.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var head []llm.Message
+	if errors.Is(err, context.Canceled) {
+		// Interrupted, not failed: try again after the next turn
+		fmt.Printf("Warning: history summarization interrupted: %v (keeping older turns)\n", err)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Warning: history summarization failed: %v (dropping older turns)\n", err)
+		head = append(head, s.messages[:start]...)
+	} else {
+		// The new note supersedes any earlier one
+		for _, msg := range s.messages[:start] {
+			if !isSummaryNote(msg) {
+				head = append(head, msg)
+			}
+		}
+		head = append(head, llm.SystemMessage(summaryNotePrefix+summary))
+	}
+	s.messages = append(head, s.messages[cut:]...)
+}
+
+// compactionRange returns the index of the first conversational message and the
+// index where the last keep turns begin. Caller must hold s.mu.
+func (s *Session) compactionRange(keep int) (int, int) {
+	start := 0
+	for start < len(s.messages) && s.messages[start].Role == llm.RoleSystem {
+		start++
+	}
+	if keep == 0 {
+		return start, len(s.messages)
+	}
+
+	cut := len(s.messages)
+	userTurns := 0
+	for i := len(s.messages) - 1; i >= start; i-- {
+		if s.messages[i].Role == llm.RoleUser {
+			userTurns++
+			cut = i
+			if userTurns == keep {
+				break
+			}
+		}
+	}
+	if userTurns < keep {
+		return start, start
+	}
+	return start, cut
+}
+
+// summarize asks the provider for a compact note covering messages
+func (s *Session) summarize(ctx context.Context, rules *config.Rules, messages []llm.Message) (string, error) {
+	var transcript strings.Builder
+	for _, msg := range messages {
+		switch {
+		case isSummaryNote(msg):
+			fmt.Fprintf(&transcript, "Earlier summary: %s\n\n", strings.TrimPrefix(msg.Content, summaryNotePrefix))
+		case msg.Role == llm.RoleUser:
+			fmt.Fprintf(&transcript, "User: %s\n\n", messageText(msg))
+		case msg.Role == llm.RoleAssistant && len(msg.ToolCalls) > 0:
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(&transcript, "Assistant called %s(%s)\n\n", call.Name, call.Arguments)
+			}
+		case msg.Role == llm.RoleAssistant:
+			fmt.Fprintf(&transcript, "Assistant: %s\n\n", msg.Content)
+		case msg.Role == llm.RoleTool:
+			fmt.Fprintf(&transcript, "Tool result: %s\n\n", msg.Content)
+		}
+	}
+
+	model := rules.History.SummaryModel
+	if model == "" {
+		model = rules.Chat.Model
+	}
+
+	resp, err := s.provider.StreamChat(ctx, llm.ChatRequest{
+		Model: model,
+		Messages: []llm.Message{
+			llm.SystemMessage(summarizePrompt),
+			llm.UserMessage(llm.TextPart(transcript.String())),
+		},
+		MaxTokens: summaryMaxTokens,
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return summary, nil
+}
+
+// messageText joins the text content of a message, skipping images
+func messageText(msg llm.Message) string {
+	texts := []string{}
+	if msg.Content != "" {
+		texts = append(texts, msg.Content)
+	}
+	for _, part := range msg.Parts {
+		if part.Type == llm.PartText && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func hasImage(msg llm.Message) bool {
+	for _, part := range msg.Parts {
+		if part.Type == llm.PartImage {
+			return true
+		}
+	}
+	return false
+}
+
+func isSummaryNote(msg llm.Message) bool {
+	return msg.Role == llm.RoleSystem && strings.HasPrefix(msg.Content, summaryNotePrefix)
+}
@@ -0,0 +1,136 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
+)
+
+// fakeProvider answers chat requests with chat, and summary requests with summarize
+type fakeProvider struct {
+	chat      func(ctx context.Context, req llm.ChatRequest, onDelta llm.DeltaFunc) (*llm.ChatResponse, error)
+	summarize func(ctx context.Context) (string, error)
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) StreamChat(ctx context.Context, req llm.ChatRequest, onDelta llm.DeltaFunc) (*llm.ChatResponse, error) {
+	if len(req.Messages) > 0 && req.Messages[0].Content == summarizePrompt {
+		summary, err := p.summarize(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &llm.ChatResponse{Content: summary}, nil
+	}
+	return p.chat(ctx, req, onDelta)
+}
+
+func (p *fakeProvider) Transcribe(ctx context.Context, audioPath string, opts llm.TranscriptionOptions) (*llm.Transcription, error) {
+	return nil, errors.New("not supported")
+}
+
+// discardWriter drops every event
+type discardWriter struct{}
+
+func (discardWriter) WriteEvent(stream.Event) error { return nil }
+func (discardWriter) Close() error                  { return nil }
+
+// newTestSession returns a session whose history is always over budget and
+// keeps one recent turn, preloaded with turns earlier exchanges
+func newTestSession(t *testing.T, provider llm.Provider, turns int) *Session {
+	t.Helper()
+	keep := 1
+	rules := &config.Rules{History: config.HistoryConfig{MaxTokens: 1, KeepRecentTurns: &keep}}
+	if err := rules.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSession(discardWriter{}, provider, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.messages = []llm.Message{llm.SystemMessage("system")}
+	for i := 0; i < turns; i++ {
+		s.messages = append(s.messages,
+			llm.UserMessage(llm.TextPart("question")),
+			llm.AssistantMessage("answer"))
+	}
+	return s
+}
+
+func TestCompactHistoryKeepsTurnsWhenCancelled(t *testing.T) {
+	provider := &fakeProvider{summarize: func(ctx context.Context) (string, error) {
+		return "", ctx.Err()
+	}}
+	s := newTestSession(t, provider, 3)
+	before := append([]llm.Message(nil), s.messages...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.compactHistory(ctx, s.activeRules())
+
+	if !reflect.DeepEqual(s.messages, before) {
+		t.Errorf("history changed after a cancelled summary:\ngot  %+v\nwant %+v", s.messages, before)
+	}
+}
+
+func TestCompactHistoryDropsTurnsWhenSummaryFails(t *testing.T) {
+	provider := &fakeProvider{summarize: func(ctx context.Context) (string, error) {
+		return "", errors.New("provider unavailable")
+	}}
+	s := newTestSession(t, provider, 3)
+
+	s.compactHistory(context.Background(), s.activeRules())
+
+	// The system prompt and the last turn remain
+	if len(s.messages) != 3 {
+		t.Errorf("got %d messages, want 3: %+v", len(s.messages), s.messages)
+	}
+}
+
+func TestCompactHistorySummarizes(t *testing.T) {
+	provider := &fakeProvider{summarize: func(ctx context.Context) (string, error) {
+		return "earlier", nil
+	}}
+	s := newTestSession(t, provider, 3)
+
+	s.compactHistory(context.Background(), s.activeRules())
+
+	if len(s.messages) != 4 || s.messages[1].Content != summaryNotePrefix+"earlier" {
+		t.Errorf("want system prompt, summary note and the last turn, got %+v", s.messages)
+	}
+}
+
+func TestCancelAfterAnswerDoesNotStopCompaction(t *testing.T) {
+	var s *Session
+	provider := &fakeProvider{
+		chat: func(ctx context.Context, req llm.ChatRequest, onDelta llm.DeltaFunc) (*llm.ChatResponse, error) {
+			onDelta("ok")
+			return &llm.ChatResponse{Content: "ok"}, nil
+		},
+		summarize: func(ctx context.Context) (string, error) {
+			// Escape pressed after the answer finished streaming
+			s.Cancel()
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+			return "earlier", nil
+		},
+	}
+	s = newTestSession(t, provider, 3)
+
+	if err := s.Process(context.Background(), Input{Text: "next"}, false); err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.messages) < 2 || s.messages[1].Content != summaryNotePrefix+"earlier" {
+		t.Errorf("history was not summarized: %+v", s.messages)
+	}
+}
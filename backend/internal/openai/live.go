@@ -0,0 +1,158 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
+)
+
+// LiveTranscript transcribes a recording segment by segment while it is still
+// being made, so only the last segment is left when the key is released
+type LiveTranscript struct {
+	s       *Session
+	id      int64
+	segment time.Duration
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	texts   []string // by segment index
+	done    []bool
+	err     error
+	shown   int  // segments in the last partial transcript
+	aborted bool // cancelled by the user before Process took over
+}
+
+// StartLiveTranscript opens a request whose audio arrives in segments through
+// Add; pass it to Process as Input.Live. It returns nil when streaming
+// transcription is off.
+func (s *Session) StartLiveTranscript() *LiveTranscript {
+	cfg := s.base.Transcription
+	if !cfg.StreamingEnabled() {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lt := &LiveTranscript{
+		s:       s,
+		id:      s.newRequestID(),
+		segment: time.Duration(cfg.SegmentSec) * time.Second,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	// Register like any request so Cancel stops the transcription; Process
+	// replaces the entry with its own once the key is released
+	s.track(lt.id, func() {
+		lt.mu.Lock()
+		lt.aborted = true
+		lt.mu.Unlock()
+		cancel()
+	})
+	s.emit(stream.Event{Type: stream.EventStarted, RequestID: lt.id, Profile: s.Profile()})
+	return lt
+}
+
+// Aborted reports whether the user cancelled the request while recording
+func (lt *LiveTranscript) Aborted() bool {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	return lt.aborted
+}
+
+// SegmentDuration is how long each recorded segment should be
+func (lt *LiveTranscript) SegmentDuration() time.Duration {
+	return lt.segment
+}
+
+// Add transcribes a segment in the background. Partial transcripts are sent
+// to the writer as soon as the segments before them are done.
+func (lt *LiveTranscript) Add(index int, path string) {
+	lt.wg.Add(1)
+	go func() {
+		defer lt.wg.Done()
+		text, err := lt.s.transcribeAudio(lt.ctx, path)
+
+		lt.mu.Lock()
+		defer lt.mu.Unlock()
+		if err != nil {
+			if lt.err == nil {
+				lt.err = fmt.Errorf("segment %d: %w", index, err)
+			}
+			return
+		}
+		for len(lt.texts) <= index {
+			lt.texts = append(lt.texts, "")
+			lt.done = append(lt.done, false)
+		}
+		lt.texts[index] = strings.TrimSpace(text)
+		lt.done[index] = true
+		lt.showPartial()
+	}()
+}
+
+// Wait returns the transcript once every segment is done. It fails if any
+// segment failed or is missing, so the caller can fall back to the full recording.
+func (lt *LiveTranscript) Wait(ctx context.Context) (string, error) {
+	defer lt.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		lt.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if lt.err != nil {
+		return "", lt.err
+	}
+	for i, ok := range lt.done {
+		if !ok {
+			return "", fmt.Errorf("segment %d is missing", i)
+		}
+	}
+	return lt.join(len(lt.texts)), nil
+}
+
+// showPartial sends the transcript of the leading segments that are done.
+// Caller must hold lt.mu.
+func (lt *LiveTranscript) showPartial() {
+	n := 0
+	for n < len(lt.done) && lt.done[n] {
+		n++
+	}
+	if n == lt.shown {
+		return
+	}
+	lt.shown = n
+
+	// Partials are shown to viewers too, so they are redacted like the final
+	// transcript; only the final one is audited
+	text, _ := lt.s.currentRedactor().Text(lt.join(n))
+	if text == "" {
+		return
+	}
+	fmt.Printf("📝 %s\n", text)
+	lt.s.emit(stream.Event{Type: stream.EventPartialTranscript, RequestID: lt.id, Text: text})
+}
+
+// join concatenates the first n segment transcripts. Caller must hold lt.mu.
+func (lt *LiveTranscript) join(n int) string {
+	var parts []string
+	for _, text := range lt.texts[:n] {
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
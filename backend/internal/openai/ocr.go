@@ -0,0 +1,36 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/ocr"
+)
+
+// screenTextHeader introduces OCR text in a user message
+const screenTextHeader = "Text on screen:\n\n"
+
+// SetOCR sets the engine used to extract text from screenshots
+func (s *Session) SetOCR(engine ocr.Engine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ocr = engine
+}
+
+// recognizeText runs OCR on a screenshot. Failures are logged and yield no
+// text so the turn can still go ahead with the image.
+func (s *Session) recognizeText(ctx context.Context, path string) string {
+	s.mu.Lock()
+	engine := s.ocr
+	s.mu.Unlock()
+
+	text, err := engine.Recognize(ctx, path)
+	if err != nil {
+		fmt.Printf("Warning: %s OCR failed for %s: %v\n", engine.Name(), path, err)
+		return ""
+	}
+	if text != "" {
+		fmt.Printf("🔤 %s OCR: %d characters\n", engine.Name(), len(text))
+	}
+	return text
+}
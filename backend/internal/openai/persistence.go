@@ -0,0 +1,105 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/conversation"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// AttachLog makes the session persist every completed turn to log
+func (s *Session) AttachLog(log *conversation.Log) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = log
+}
+
+// Resume rebuilds the conversation from a stored log and keeps appending to it.
+// Screenshots are re-encoded only for the turns that would keep their image.
+func (s *Session) Resume(ctx context.Context, log *conversation.Log) error {
+	turns, err := log.Turns()
+	if err != nil {
+		return fmt.Errorf("failed to read session %s: %w", log.ID, err)
+	}
+
+	rules := s.activeRules()
+	messages := []llm.Message{llm.SystemMessage(buildSystemPrompt(rules, len(turns)))}
+	vision := rules.Chat.SupportsVision()
+	for i, turn := range turns {
+		keepImage := len(turns)-i <= rules.History.ImagesKept()
+
+		var parts []llm.Part
+		images := turn.Images()
+		if vision {
+			for _, shot := range images {
+				if len(images) > 1 && shot.Label != "" {
+					parts = append(parts, llm.TextPart(shot.Label+":"))
+				}
+				parts = append(parts, s.restoreImage(shot.Path, keepImage, rules.Image))
+			}
+		}
+		if turn.ScreenText != "" {
+			parts = append(parts, llm.TextPart(screenTextHeader+turn.ScreenText))
+		}
+		if turn.Transcript != "" {
+			parts = append(parts, llm.TextPart(fmt.Sprintf("Transcript:\n\n%s", turn.Transcript)))
+		}
+		if turn.Text != "" {
+			parts = append(parts, llm.TextPart(turn.Text))
+		}
+		if len(parts) > 0 {
+			messages = append(messages, llm.UserMessage(parts...))
+		}
+		messages = append(messages, llm.AssistantMessage(turn.Reply))
+	}
+
+	s.mu.Lock()
+	s.messages = messages
+	s.log = log
+	s.turnCount = len(turns)
+	s.mu.Unlock()
+
+	fmt.Printf("📂 Resumed session %s (%d turns)\n", log.ID, len(turns))
+
+	// A resumed log may be far larger than the budget
+	s.compactHistory(ctx, rules)
+	return nil
+}
+
+// restoreImage re-encodes a stored screenshot, or returns a placeholder when
+// the image isn't needed or the file has since been cleared
+func (s *Session) restoreImage(path string, keep bool, image config.ImageConfig) llm.Part {
+	if !keep {
+		return llm.TextPart(omittedImageText)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return llm.TextPart(omittedImageText)
+	}
+
+	img, err := compressAndEncodeImage(path, image)
+	if err != nil {
+		fmt.Printf("Warning: failed to restore screenshot %s: %v\n", path, err)
+		return llm.TextPart(omittedImageText)
+	}
+	return llm.ImagePart(img.DataURI)
+}
+
+// persistTurn appends a completed turn to the attached log, if any
+func (s *Session) persistTurn(turn conversation.Turn) {
+	s.mu.Lock()
+	log := s.log
+	s.mu.Unlock()
+
+	if log == nil {
+		return
+	}
+
+	turn.Time = time.Now()
+	if err := log.Append(turn); err != nil {
+		fmt.Printf("Warning: failed to persist turn: %v\n", err)
+	}
+}
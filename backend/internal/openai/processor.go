@@ -1,255 +1,450 @@
 package openai
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"image"
-	"image/jpeg"
-	_ "image/png" // support PNG
 	"os"
-	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/glamour"
-	openai "github.com/openai/openai-go/v2"
-	"github.com/openai/openai-go/v2/option"
 
+	"github.com/PeterShin23/MyAssistant/backend/internal/cache"
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/conversation"
+	"github.com/PeterShin23/MyAssistant/backend/internal/imageproc"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/ocr"
+	"github.com/PeterShin23/MyAssistant/backend/internal/queue"
+	"github.com/PeterShin23/MyAssistant/backend/internal/redact"
+	"github.com/PeterShin23/MyAssistant/backend/internal/screen"
 	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
-	// "github.com/openai/openai-go/v2/shared"
+	"github.com/PeterShin23/MyAssistant/backend/internal/tools"
+	"github.com/PeterShin23/MyAssistant/backend/internal/transcribe"
+	"github.com/PeterShin23/MyAssistant/backend/internal/usage"
 )
 
-var rulesJSON = filepath.Join(projectRoot(), "rules.json")
+type Session struct {
+	mu          sync.Mutex
+	compactMu   sync.Mutex // serializes history compaction
+	provider    llm.Provider
+	base        *config.Rules                // rules.json as loaded, before any profile
+	rules       atomic.Pointer[config.Rules] // base with the active profile applied
+	messages    []llm.Message
+	writer      stream.StreamWriter
+	log         *conversation.Log
+	tools       *tools.Registry
+	ocr         ocr.Engine
+	transcriber transcribe.Transcriber // nil uses the provider
+	cache       *cache.Cache
+	redactor    *redact.Redactor // nil sends captures unchanged
+	queueing    bool             // queue captures the provider couldn't answer
+	capturing   bool             // the hotkey is held, see SetCapturing
+
+	inflight     map[int64]context.CancelFunc // cancel funcs of running requests
+	requestCount int64
+	turnCount    int // completed turns, for the system prompt
+}
+
+func NewSession(writer stream.StreamWriter, provider llm.Provider, rules *config.Rules) (*Session, error) {
+	if provider == nil {
+		return nil, errors.New("no LLM provider configured")
+	}
+	if rules == nil {
+		rules = &config.Rules{}
+		if err := rules.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	active, err := rules.ForProfile(rules.Profile)
+	if err != nil {
+		return nil, err
+	}
 
-func projectRoot() string {
-	dir, _ := os.Getwd()
-	return dir
+	s := &Session{
+		provider: provider,
+		base:     rules,
+		messages: []llm.Message{},
+		writer:   writer,
+		ocr:      ocr.Noop{},
+	}
+	s.rules.Store(active)
+	return s, nil
 }
 
-type Session struct {
-	mu       sync.Mutex
-	client   openai.Client
-	messages []openai.ChatCompletionMessageParamUnion
-	writer   stream.StreamWriter
+// Provider returns the LLM provider backing this session
+func (s *Session) Provider() llm.Provider {
+	return s.provider
 }
 
-type PromptConfig struct {
-	TechnicalPrompt string `json:"whatDoYouNeedHelpWith"`
+// Input is everything captured for a single turn
+type Input struct {
+	// Screenshots are sent in order as labelled image parts
+	Screenshots []screen.Screenshot
+	AudioPath   string
+	// Live holds the transcript streamed while recording; AudioPath is then
+	// only transcribed if streaming failed
+	Live *LiveTranscript
+	// Text is a typed question, sent after the capture
+	Text string
+	// Transcript replaces transcribing AudioPath, e.g. for a queued capture
+	// that was transcribed before it failed
+	Transcript string
+	// Profile runs the turn with this profile instead of the active one
+	Profile string
+	// CapturedAt is set when retrying a queued capture. The answer is then
+	// marked as late and a failure is not queued again.
+	CapturedAt time.Time
 }
 
-func NewSession(writer stream.StreamWriter) (*Session, error) {
-   apiKey := os.Getenv("OPENAI_API_KEY")
+// ErrNothingToSend is returned by Process when a turn has no screenshot,
+// screen text, transcript or typed text
+var ErrNothingToSend = errors.New("nothing to send: no screenshot, transcript or text")
+
+// Process sends a capture to the provider and streams the answer to the writer.
+// The request can be stopped through ctx or Session.Cancel, in which case
+// Process returns an error wrapping ErrCancelled.
+//
+// The writer sees a started event, then the answer, then exactly one of
+// completed, cancelled or error, all tagged with the request's ID.
+func (s *Session) Process(ctx context.Context, in Input, pretty bool) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// A live transcript already opened the request
+	var id int64
+	if in.Live != nil {
+		id = in.Live.id
+	} else {
+		id = s.newRequestID()
+	}
+	s.track(id, cancel)
+	defer s.untrack(id)
+	if in.Live != nil {
+		defer in.Live.cancel()
+	}
+	if in.Live != nil && in.Live.Aborted() {
+		// Escape was pressed while the key was still held
+		err := fmt.Errorf("recording: %w", ErrCancelled)
+		s.emitFailed(id, err)
+		return err
+	}
 
-   if apiKey == "" {
-	   return nil, errors.New("OPENAI_API_KEY not set")
-   }
+	// A profile switch mid-request applies from the next request
+	rules := s.activeRules()
+	if in.Profile != "" && in.Profile != rules.ActiveProfile {
+		if profiled, perr := s.base.ForProfile(in.Profile); perr != nil {
+			fmt.Printf("Warning: %v (using the active profile)\n", perr)
+		} else {
+			rules = profiled
+		}
+	}
+	ctx = usage.WithProfile(ctx, rules.ActiveProfile)
+	received := time.Now()
+
+	if in.Live == nil {
+		started := stream.Event{Type: stream.EventStarted, RequestID: id, Profile: rules.ActiveProfile}
+		if !in.CapturedAt.IsZero() {
+			started.Late = true
+			started.Text = "Late answer to the capture from " + in.CapturedAt.Local().Format("Jan 2 15:04:05")
+		} else {
+			started.FollowUp = len(in.Screenshots) == 0 && in.AudioPath == "" && in.Transcript == ""
+		}
+		s.emit(started)
+	}
+	defer func() {
+		if err != nil {
+			s.emitFailed(id, err)
+		}
+	}()
+
+	// Every modality is optional: a turn is whatever mix of screenshots,
+	// transcript and typed text is available. Missing screenshots are dropped.
+	var screenshots []screen.Screenshot
+	for _, shot := range in.Screenshots {
+		if err := waitForFileWithRetry(ctx, shot.Path, 5, 2*time.Second); err != nil {
+			if cerr := cancelled(ctx, "waiting for screenshot"); cerr != nil {
+				return cerr
+			}
+			fmt.Printf("Warning: screenshot not available: %v (continuing without it)\n", err)
+			continue
+		}
+		path, err := s.maskScreenshot(id, shot.Path)
+		if err != nil {
+			return err
+		}
+		shot.Path = path
+		screenshots = append(screenshots, shot)
+	}
 
-   client := openai.NewClient(option.WithAPIKey(apiKey))
+	audioPath := in.AudioPath
 
-   return &Session{
-	   client:   client,
-	   messages: []openai.ChatCompletionMessageParamUnion{},
-	   writer:   writer,
-   }, nil
-}
+	// 1. Transcribe audio (if available)
+	transcript := in.Transcript
+	transcribeFile := audioPath != "" && transcript == ""
+	if in.Live != nil {
+		live, err := in.Live.Wait(ctx)
+		if cerr := cancelled(ctx, "transcription"); cerr != nil {
+			return cerr
+		}
+		if err != nil {
+			fmt.Printf("Streaming transcription failed: %v (transcribing the full recording)\n", err)
+		} else {
+			transcript, transcribeFile = live, false
+		}
+	}
+	if transcribeFile {
+		if err := waitForFileWithRetry(ctx, audioPath, 5, 2*time.Second); err != nil {
+			fmt.Printf("Audio file not available: %v (continuing without audio)\n", err)
+		} else {
+			transcript, err = s.transcribeAudio(ctx, audioPath)
+			if err != nil {
+				fmt.Printf("transcription failed: %v (continuing without transcript)\n", err)
+			}
+		}
+		if err := cancelled(ctx, "transcription"); err != nil {
+			return err
+		}
+	}
 
-func (s *Session) Process(screenshotPath, audioPath string, pretty bool) error {
-   ctx := context.Background()
-
-   // Wait for screenshot file with retry
-   if err := waitForFileWithRetry(screenshotPath, 5, 2*time.Second); err != nil {
-     return fmt.Errorf("screenshot file not available: %w", err)
-   }
-
-   // 1. Transcribe audio using Whisper (if available)
-   var transcript string
-   if audioPath != "" {
-     if err := waitForFileWithRetry(audioPath, 5, 2*time.Second); err != nil {
-       fmt.Printf("Audio file not available: %v (continuing without audio)\n", err)
-     } else {
-       transcript, err = s.transcribeAudio(ctx, audioPath)
-       if err != nil {
-         fmt.Printf("transcription failed: %v (continuing without transcript)\n", err)
-       }
-     }
-   }
-
-   // 2. Compress and encode screenshot as JPEG base64 data URI
-   dataURI, err := compressAndEncodeImage(screenshotPath)
-   if err != nil {
-     return fmt.Errorf("failed to compress image: %w", err)
-   }
-
-   // Prepare image content part for OpenAI vision
-   imagePart := openai.ChatCompletionContentPartUnionParam{
-     OfImageURL: &openai.ChatCompletionContentPartImageParam{
-       ImageURL: openai.ChatCompletionContentPartImageImageURLParam{
-         URL:   dataURI,
-         Detail: "auto",
-       },
-     },
-   }
-
-   // Prepare transcript content part (if any)
-   var contentParts []openai.ChatCompletionContentPartUnionParam
-   contentParts = append(contentParts, imagePart)
-   if transcript != "" {
-     fmt.Printf("transcript: %s\n", transcript)
-     contentParts = append(contentParts, openai.TextContentPart(fmt.Sprintf("Transcript:\n\n%s", transcript)))
-   }
-
-   // Prepare user message
-   userMessage := openai.UserMessage(contentParts)
-
-   // Append system message once and user message for this request
-   s.mu.Lock()
-   if len(s.messages) == 0 {
-     var systemPrompt = buildSystemPrompt()
-     s.messages = append(s.messages, openai.SystemMessage(systemPrompt))
-   }
-   s.messages = append(s.messages, userMessage)
-   s.mu.Unlock()
-
-   params := openai.ChatCompletionNewParams{
-     Messages: s.messages,
-     Model:    "gpt-4.1", // shared.ChatModelGPT5Mini,
-    //  MaxCompletionTokens: openai.Int(4096),
-   }
-
-   stream := s.client.Chat.Completions.NewStreaming(ctx, params)
-   defer stream.Close()
-
-   fmt.Print("🤖 GPT Response:\n")
-
-   var fullContent string
-   chunkCount := 0
-   for stream.Next() {
-     chunk := stream.Current()
-     if len(chunk.Choices) > 0 {
-       delta := chunk.Choices[0].Delta.Content
-       if delta != "" {
-         chunkCount++
-				//  fmt.Print(delta)
-        //  fmt.Printf("[Processor] Processing chunk %d: %q\n", chunkCount, delta)
-         if s.writer != nil {
-           if err := s.writer.WriteChunk(delta); err != nil {
-             // Log error but continue processing
-             fmt.Printf("Warning: failed to write chunk %d to stream: %v\n", chunkCount, err)
-           }
-         }
-         fullContent += delta
-       }
-     }
-   }
-   if err := stream.Err(); err != nil {
-     return fmt.Errorf("stream error: %w", err)
-   }
-
-   fmt.Printf("[Processor] Stream completed. Total chunks received: %d, total content length: %d\n", chunkCount, len(fullContent))
-
-   // Mark stream as complete but keep the connection open for next request
-   if s.writer != nil {
-     fmt.Printf("[Processor] Marking stream complete after %d chunks (keeping connection open)\n", chunkCount)
-     
-     if err := s.writer.MarkStreamComplete(); err != nil {
-       fmt.Printf("Warning: failed to mark stream complete: %v\n", err)
-     } else {
-       fmt.Printf("[Processor] Stream marked as successfully\n")
-     }
-   }
-
-   // Maintain Session Context - add assistant response to conversation
-   s.mu.Lock()
-   s.messages = append(s.messages, openai.AssistantMessage(fullContent))
-   s.mu.Unlock()
-
-   return nil
-}
+	// 2. Preprocess and encode screenshots as JPEG base64 data URIs, with any
+	// OCR text next to them. Models without vision only get the text.
+	// Labels are only needed to tell several images apart.
+	vision := rules.Chat.SupportsVision()
+	var contentParts []llm.Part
+	var screenTexts []string
+	var imageDigests []string
+	for _, shot := range screenshots {
+		labelled := len(screenshots) > 1 && shot.Label != ""
+		if labelled {
+			contentParts = append(contentParts, llm.TextPart(shot.Label+":"))
+		}
+
+		if vision {
+			img, err := compressAndEncodeImage(shot.Path, rules.Image)
+			if err != nil {
+				return fmt.Errorf("failed to compress image: %w", err)
+			}
+			fmt.Printf("🖼️  Image %s: %dx%d, %.1f KB, ~%d tokens\n",
+				shot.Label, img.Width, img.Height, float64(img.Bytes)/1024,
+				llm.EstimateImageTokensForSize(img.Width, img.Height, rules.Chat.ImageDetail))
+			contentParts = append(contentParts, llm.ImagePart(img.DataURI))
+			imageDigests = append(imageDigests, img.Digest)
+		}
 
-func buildSystemPrompt() string {
-	systemPrompt := `You are the user's personal helper. 
-	Use the image and audio transcript provided as context. 
-	Assume that the user needs help with the context that's provided to you.
-	Make the best assumption about what the user needs help with.
-	Always validate your own answer.
-	Avoid polite or generic statements like "let me know if you have other questions" or "feel free to ask".
-	Only respond with the most relevant, concise, and helpful information.`
-
-	// Load technical user prompt from JSON
-	technicalPrompt := `I need general help with various tasks.`
-	promptBytes, err := os.ReadFile(rulesJSON)
-	if err == nil {
-		var cfg PromptConfig
-		if err := json.Unmarshal(promptBytes, &cfg); err == nil && cfg.TechnicalPrompt != "" {
-			technicalPrompt = cfg.TechnicalPrompt
+		text := s.recognizeText(ctx, shot.Path)
+		if err := cancelled(ctx, "ocr"); err != nil {
+			return err
 		}
+		text = s.redactText(id, redact.SourceScreenText, text)
+		if text == "" {
+			if !vision {
+				fmt.Printf("Warning: the model has no vision and no text was recognized in %s\n", shot.Path)
+			}
+			continue
+		}
+		contentParts = append(contentParts, llm.TextPart(screenTextHeader+text))
+		if labelled {
+			text = shot.Label + ":\n" + text
+		}
+		screenTexts = append(screenTexts, text)
+	}
+
+	// Prepare transcript content part (if any)
+	transcript = s.redactText(id, redact.SourceTranscript, transcript)
+	if transcript != "" {
+		fmt.Printf("transcript: %s\n", transcript)
+		s.emit(stream.Event{Type: stream.EventTranscript, RequestID: id, Text: transcript})
+		contentParts = append(contentParts, llm.TextPart(fmt.Sprintf("Transcript:\n\n%s", transcript)))
+	}
+	in.Text = s.redactText(id, redact.SourceText, in.Text)
+	if in.Text != "" {
+		contentParts = append(contentParts, llm.TextPart(in.Text))
 	}
 
-	return fmt.Sprintf(`%s
+	if len(contentParts) == 0 {
+		return ErrNothingToSend
+	}
 
-	What the user needs help with: %s`, systemPrompt, technicalPrompt)
-}
+	// Prepare user message
+	userMessage := llm.UserMessage(contentParts...)
+
+	// Send the history with this request's user message. The conversation
+	// itself only gains the turn once it finishes, so a failed or queued
+	// request leaves no unanswered message behind.
+	s.mu.Lock()
+	s.refreshSystemPrompt(rules)
+	messages := append(append([]llm.Message(nil), s.messages...), userMessage)
+	toolDefs := s.tools.Definitions()
+	s.mu.Unlock()
+	trimImages(messages, rules.History.ImagesKept())
+
+	if rules.ActiveProfile != "" {
+		fmt.Printf("🤖 %s Response [%s]:\n", s.provider.Name(), rules.ActiveProfile)
+	} else {
+		fmt.Printf("🤖 %s Response:\n", s.provider.Name())
+	}
 
-func (s *Session) transcribeAudio(ctx context.Context, audioPath string) (string, error) {
-   if audioPath == "" {
-	   return "", nil
-   }
-
-   file, err := os.Open(audioPath)
-   if err != nil {
-	   return "", err
-   }
-   defer file.Close()
-
-   params := openai.AudioTranscriptionNewParams{
-	   File:  file,
-	   Model: "whisper-1",
-   }
-   resp, err := s.client.Audio.Transcriptions.New(ctx, params)
-   if err != nil {
-	   return "", err
-   }
-   return resp.Text, nil
+	// Replay the stored answer when the screen, question and conversation haven't changed
+	key := s.cacheKey(rules, messages[:len(messages)-1], imageDigests, screenTexts, transcript, in.Text)
+	turn := []llm.Message{userMessage}
+	fullContent, hit := s.replayCached(id, rules, key)
+	if !hit {
+		var toolMessages []llm.Message
+		fullContent, toolMessages, err = s.generate(ctx, id, rules, messages, toolDefs)
+		turn = append(turn, toolMessages...)
+		if errors.Is(err, ErrCancelled) {
+			s.finishCancelled(rules, turn, fullContent)
+			return err
+		}
+		if err != nil {
+			// Keep the capture for a later retry when the provider was
+			// unreachable; errors such as a bad API key won't go away by waiting
+			if in.CapturedAt.IsZero() && llm.Classify(err) == llm.ClassRetryable {
+				err = s.enqueue(queue.Item{
+					Created:     received,
+					Profile:     rules.ActiveProfile,
+					Screenshots: screenshots,
+					AudioPath:   audioPath,
+					Transcript:  transcript,
+					Text:        in.Text,
+				}, err)
+			}
+			return err
+		}
+		s.storeCached(rules, key, fullContent)
+	}
+
+	// Close the request but keep the connection open for the next one
+	s.emit(stream.Event{Type: stream.EventCompleted, RequestID: id})
+
+	// Maintain Session Context - add the turn and its answer to the conversation
+	s.commitTurn(rules, append(turn, llm.AssistantMessage(fullContent))...)
+	s.mu.Lock()
+	s.turnCount++
+	s.mu.Unlock()
+
+	s.persistTurn(conversation.Turn{
+		Screenshots: screenshots,
+		AudioPath:   audioPath,
+		ScreenText:  strings.Join(screenTexts, "\n\n"),
+		Transcript:  transcript,
+		Text:        in.Text,
+		Reply:       fullContent,
+	})
+
+	// Keep the next request within the token budget. The answer is complete,
+	// so a cancel from here on must not interrupt the summary.
+	s.untrack(id)
+	s.compactHistory(context.WithoutCancel(ctx), rules)
+
+	return nil
 }
 
-func compressAndEncodeImage(path string) (string, error) {
-	// Read the file into memory
-	imgBytes, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
+// generate streams the answer to messages from the provider, running any tool
+// calls the model makes, and returns the full answer with the tool messages
+// of the turn. A cancelled request returns the partial answer with the error.
+func (s *Session) generate(ctx context.Context, id int64, rules *config.Rules, messages []llm.Message, toolDefs []llm.ToolDefinition) (string, []llm.Message, error) {
+	req := llm.ChatRequest{
+		Model:       rules.Chat.Model,
+		Messages:    messages,
+		Temperature: rules.Chat.Temperature,
+		MaxTokens:   rules.Chat.MaxTokens,
+		ImageDetail: rules.Chat.ImageDetail,
 	}
 
-	// Decode image format dynamically
-	img, _, err := image.Decode(bytes.NewReader(imgBytes))
-	if err != nil {
-		return "", err
+	// Structured answers are validated before they reach the writers
+	structured := rules.Structured
+	if structured != nil {
+		req.ResponseSchema = &llm.ResponseSchema{
+			Name:        structured.Name,
+			Description: structured.Description,
+			Schema:      structured.Schema,
+			Strict:      structured.Strict,
+		}
 	}
 
-	// Compress to JPEG (low quality) in memory
-	var buf bytes.Buffer
-	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
-		return "", err
+	chunkCount := 0
+	var fullContent string
+	var turn []llm.Message
+	// Structured answers take no deltas, so the provider can still retry a
+	// stream that fails halfway
+	var onDelta llm.DeltaFunc
+	if structured == nil {
+		onDelta = func(delta string) {
+			chunkCount++
+			fullContent += delta
+			s.emit(stream.Event{Type: stream.EventDelta, RequestID: id, Text: delta})
+		}
+	}
+
+	// Usage is summed over the tool rounds
+	var usage llm.Usage
+	defer func() { s.emitUsage(id, usage) }()
+
+	// Let the model call local tools until it answers; the last round
+	// offers no tools so it has to
+	for round := 1; ; round++ {
+		req.Tools = nil
+		if round < rules.Tools.MaxRounds {
+			req.Tools = toolDefs
+		}
+
+		resp, err := s.provider.StreamChat(ctx, req, onDelta)
+		if resp != nil {
+			usage.PromptTokens += resp.Usage.PromptTokens
+			usage.CompletionTokens += resp.Usage.CompletionTokens
+			usage.CachedTokens += resp.Usage.CachedTokens
+		}
+		if err != nil {
+			if cerr := cancelled(ctx, "stream"); cerr != nil {
+				return fullContent, turn, cerr
+			}
+			return "", turn, fmt.Errorf("stream error: %w", err)
+		}
+		if len(resp.ToolCalls) == 0 {
+			if structured != nil {
+				fullContent = resp.Content
+				s.deliverStructured(id, structured, fullContent)
+			}
+			break
+		}
+
+		toolMessages := s.runToolCalls(ctx, id, resp)
+		if err := cancelled(ctx, "tool call"); err != nil {
+			return fullContent, turn, err
+		}
+		req.Messages = append(req.Messages, toolMessages...)
+		turn = append(turn, toolMessages...)
 	}
 
-	// Convert to base64 data URI
-	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
-	dataURI := "data:image/jpeg;base64," + encoded
-	return dataURI, nil
+	fmt.Printf("[Processor] Stream completed. Total chunks received: %d, total content length: %d\n", chunkCount, len(fullContent))
+	return fullContent, turn, nil
+}
+
+// compressAndEncodeImage runs the request's preprocessing pipeline and
+// returns the screenshot as a JPEG base64 data URI
+func compressAndEncodeImage(path string, image config.ImageConfig) (*imageproc.Result, error) {
+	return imageproc.Process(path, image.Options())
 }
 
 // waitForFileWithRetry waits for a file to exist with retry logic
-func waitForFileWithRetry(filePath string, maxRetries int, delay time.Duration) error {
+func waitForFileWithRetry(ctx context.Context, filePath string, maxRetries int, delay time.Duration) error {
 	for i := 0; i < maxRetries; i++ {
 		if _, err := os.Stat(filePath); err == nil {
 			return nil // File exists
 		}
-		
+
 		if i < maxRetries-1 {
-			time.Sleep(delay)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
 		}
 	}
 	return fmt.Errorf("file %s does not exist after %d retries", filePath, maxRetries)
@@ -0,0 +1,64 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// activeRules returns the rules with the active profile applied
+func (s *Session) activeRules() *config.Rules {
+	return s.rules.Load()
+}
+
+// Profile returns the name of the active profile ("" when none is selected)
+func (s *Session) Profile() string {
+	return s.activeRules().ActiveProfile
+}
+
+// SetProfile switches to the named profile. The new prompt replaces the
+// system message, so the conversation so far is kept.
+func (s *Session) SetProfile(name string) error {
+	active, err := s.base.ForProfile(name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules.Store(active)
+	if len(s.messages) > 0 {
+		s.refreshSystemPrompt(active)
+	}
+
+	fmt.Printf("🎭 Profile: %s\n", profileLabel(name))
+	return nil
+}
+
+// CycleProfile switches to the next profile in alphabetical order and returns its name
+func (s *Session) CycleProfile() (string, error) {
+	names := s.base.ProfileNames()
+	if len(names) == 0 {
+		return "", errors.New("no profiles defined in rules.json")
+	}
+
+	next := names[0]
+	current := s.Profile()
+	for i, name := range names {
+		if name == current {
+			next = names[(i+1)%len(names)]
+			break
+		}
+	}
+	return next, s.SetProfile(next)
+}
+
+// profileLabel names a profile for output
+func profileLabel(name string) string {
+	if name == "" {
+		return "(none)"
+	}
+	return name
+}
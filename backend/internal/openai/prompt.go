@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// defaultSystemPrompt is used when rules.json sets no systemPrompt
+const defaultSystemPrompt = `You are the user's personal helper. 
+	Use whatever is provided as context: screenshots, a transcript of what the user said, typed text, or any mix of them. 
+	Assume that the user needs help with the context that's provided to you.
+	Make the best assumption about what the user needs help with.
+	Always validate your own answer.
+	Avoid polite or generic statements like "let me know if you have other questions" or "feel free to ask".
+	Only respond with the most relevant, concise, and helpful information.
+
+	What the user needs help with: {{.Prompt}}`
+
+// defaultHelpPrompt fills .Prompt when whatDoYouNeedHelpWith is empty
+const defaultHelpPrompt = `I need general help with various tasks.`
+
+// promptData is what the system prompt template can refer to
+type promptData struct {
+	// Now is the time the prompt was rendered; Date, Time and Weekday are preformatted
+	Now     time.Time
+	Date    string
+	Time    string
+	Weekday string
+	// OS is the operating system, e.g. "darwin"
+	OS string
+	// Display is the first captured display, Displays all of them
+	Display  int
+	Displays []int
+	// Profile is the active profile ("" when none)
+	Profile string
+	// TurnCount is the number of turns completed so far in this session
+	TurnCount int
+	// Prompt is whatDoYouNeedHelpWith, or the active profile's prompt
+	Prompt string
+	// Vars are the user-defined "vars" from rules.json
+	Vars map[string]string
+}
+
+// buildSystemPrompt renders the system prompt template for the next request.
+// A template that fails to render falls back to the default prompt.
+func buildSystemPrompt(rules *config.Rules, turnCount int) string {
+	now := time.Now()
+	displays := rules.CaptureDisplays()
+	data := promptData{
+		Now:       now,
+		Date:      now.Format("2006-01-02"),
+		Time:      now.Format("15:04"),
+		Weekday:   now.Weekday().String(),
+		OS:        runtime.GOOS,
+		Display:   displays[0],
+		Displays:  displays,
+		Profile:   rules.ActiveProfile,
+		TurnCount: turnCount,
+		Prompt:    rules.WhatDoYouNeedHelpWith,
+		Vars:      rules.Vars,
+	}
+	if data.Prompt == "" {
+		data.Prompt = defaultHelpPrompt
+	}
+
+	text := rules.SystemPrompt
+	if text == "" {
+		text = defaultSystemPrompt
+	}
+
+	prompt, err := renderPrompt(text, data)
+	if err != nil {
+		fmt.Printf("Warning: failed to render system prompt, using the default: %v\n", err)
+		prompt, _ = renderPrompt(defaultSystemPrompt, data)
+	}
+	return prompt
+}
+
+func renderPrompt(text string, data promptData) (string, error) {
+	tmpl, err := template.New("systemPrompt").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// refreshSystemPrompt re-renders the system message so time, profile and turn
+// count are current, adding it on the first request. Caller must hold s.mu.
+func (s *Session) refreshSystemPrompt(rules *config.Rules) {
+	prompt := llm.SystemMessage(buildSystemPrompt(rules, s.turnCount))
+	if len(s.messages) == 0 {
+		s.messages = append(s.messages, prompt)
+		return
+	}
+	if s.messages[0].Role == llm.RoleSystem && !isSummaryNote(s.messages[0]) {
+		s.messages[0] = prompt
+	}
+}
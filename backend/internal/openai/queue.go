@@ -0,0 +1,144 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/queue"
+)
+
+// ErrQueued is returned by Process, wrapping the provider error, when a
+// capture could not be answered and was queued for a later retry
+var ErrQueued = errors.New("capture queued for retry")
+
+// SetQueueing makes Process queue captures the provider could not answer
+// instead of dropping them. See RetryQueued.
+func (s *Session) SetQueueing(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queueing = on
+}
+
+// enqueue stores a failed capture and wraps cause with ErrQueued. Without
+// queueing, or if the capture can't be stored, cause is returned as is.
+func (s *Session) enqueue(item queue.Item, cause error) error {
+	s.mu.Lock()
+	on := s.queueing
+	s.mu.Unlock()
+	if !on {
+		return cause
+	}
+
+	stored, err := queue.Add(item)
+	if err != nil {
+		fmt.Printf("Warning: failed to queue capture: %v\n", err)
+		return cause
+	}
+	return fmt.Errorf("%w as %s: %w", ErrQueued, stored.ID, cause)
+}
+
+// RetryItem sends a queued capture again and delivers the answer marked as
+// late. The capture is dropped from the queue once answered; otherwise the
+// failed attempt is recorded on it.
+func (s *Session) RetryItem(ctx context.Context, item queue.Item, pretty bool) error {
+	err := s.Process(ctx, Input{
+		Screenshots: item.Screenshots,
+		AudioPath:   item.AudioPath,
+		Transcript:  item.Transcript,
+		Text:        item.Text,
+		Profile:     item.Profile,
+		CapturedAt:  item.Created,
+	}, pretty)
+	if err == nil {
+		return queue.Drop(item.ID)
+	}
+	if errors.Is(err, ErrCancelled) {
+		return err
+	}
+
+	item.Attempts++
+	item.LastAttempt = time.Now()
+	item.LastError = err.Error()
+	if uerr := queue.Update(item); uerr != nil {
+		fmt.Printf("Warning: failed to update queued capture %s: %v\n", item.ID, uerr)
+	}
+	return err
+}
+
+// RetryQueued sends queued captures oldest first, skipping those that have
+// failed maxAttempts times (0 means no limit). It stops at the first retryable
+// failure, since the provider is most likely still unreachable; a capture the
+// provider rejects has its attempt recorded and the next one is tried. It
+// returns how many captures were answered.
+func (s *Session) RetryQueued(ctx context.Context, maxAttempts int, pretty bool) (int, error) {
+	items, err := queue.List()
+	if err != nil {
+		return 0, err
+	}
+
+	answered := 0
+	for _, item := range items {
+		if maxAttempts > 0 && item.Attempts >= maxAttempts {
+			continue
+		}
+		fmt.Printf("📤 Retrying queued capture %s (attempt %d)\n", item.ID, item.Attempts+1)
+		if err := s.RetryItem(ctx, item, pretty); err != nil {
+			if errors.Is(err, ErrCancelled) || ctx.Err() != nil || llm.Classify(err) == llm.ClassRetryable {
+				return answered, err
+			}
+			fmt.Printf("Warning: queued capture %s failed: %v\n", item.ID, err)
+			continue
+		}
+		answered++
+	}
+	return answered, nil
+}
+
+// maxRetryBackoff caps the wait between rounds while the provider stays unreachable
+const maxRetryBackoff = 10 * time.Minute
+
+// RunQueue retries queued captures every interval until ctx is done, backing
+// off while rounds keep failing. Rounds are skipped while the hotkey is held
+// or another request is running, so late answers don't interleave with a live one.
+func (s *Session) RunQueue(ctx context.Context, interval time.Duration, maxAttempts int, pretty bool) {
+	delay := interval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if s.busy() {
+			continue
+		}
+		answered, err := s.RetryQueued(ctx, maxAttempts, pretty)
+		if answered > 0 {
+			fmt.Printf("📬 Delivered %d late answer(s)\n", answered)
+		}
+		if err != nil && !errors.Is(err, ErrCancelled) {
+			fmt.Printf("📥 Queued captures still waiting: %v\n", err)
+			delay = min(delay*2, max(interval, maxRetryBackoff))
+		} else {
+			delay = interval
+		}
+	}
+}
+
+// SetCapturing tells the session that the hotkey is held, from the key press
+// until the capture has been processed. Queued captures wait until then.
+func (s *Session) SetCapturing(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capturing = on
+}
+
+// busy reports whether a capture is being recorded or any request is in flight
+func (s *Session) busy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capturing || len(s.inflight) > 0
+}
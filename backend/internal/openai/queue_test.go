@@ -0,0 +1,85 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/queue"
+)
+
+// queueItems stores a text-only capture for each question, oldest first
+func queueItems(t *testing.T, questions ...string) {
+	t.Helper()
+	queue.Dir = t.TempDir()
+	created := time.Now().Add(-time.Hour)
+	for _, q := range questions {
+		if _, err := queue.Add(queue.Item{Created: created, Text: q}); err != nil {
+			t.Fatal(err)
+		}
+		created = created.Add(time.Second)
+	}
+}
+
+// answerUnless rejects the questions in reject with err and answers the rest
+func answerUnless(reject map[string]error) *fakeProvider {
+	return &fakeProvider{
+		chat: func(ctx context.Context, req llm.ChatRequest, onDelta llm.DeltaFunc) (*llm.ChatResponse, error) {
+			last := req.Messages[len(req.Messages)-1]
+			for _, part := range last.Parts {
+				if err, ok := reject[part.Text]; ok {
+					return nil, err
+				}
+			}
+			onDelta("ok")
+			return &llm.ChatResponse{Content: "ok"}, nil
+		},
+		summarize: func(ctx context.Context) (string, error) {
+			return "earlier", nil
+		},
+	}
+}
+
+func TestRetryQueuedSkipsRejectedCapture(t *testing.T) {
+	queueItems(t, "bad", "good")
+	provider := answerUnless(map[string]error{
+		"bad": &llm.Error{Class: llm.ClassBadRequest, StatusCode: 400, Err: errors.New("rejected")},
+	})
+	s := newTestSession(t, provider, 0)
+
+	answered, err := s.RetryQueued(context.Background(), 0, false)
+	if err != nil || answered != 1 {
+		t.Fatalf("RetryQueued = %d, %v; want 1, nil", answered, err)
+	}
+
+	items, err := queue.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Text != "bad" || items[0].Attempts != 1 || items[0].LastError == "" {
+		t.Errorf("want only the rejected capture left with its attempt recorded, got %+v", items)
+	}
+}
+
+func TestRetryQueuedStopsWhenUnreachable(t *testing.T) {
+	queueItems(t, "first", "second")
+	provider := answerUnless(map[string]error{
+		"first": &llm.Error{Class: llm.ClassRetryable, StatusCode: 503, Err: errors.New("unavailable")},
+	})
+	s := newTestSession(t, provider, 0)
+
+	answered, err := s.RetryQueued(context.Background(), 0, false)
+	if err == nil || answered != 0 {
+		t.Fatalf("RetryQueued = %d, %v; want 0 and the provider error", answered, err)
+	}
+
+	items, err := queue.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Errorf("got %d queued captures, want both kept", len(items))
+	}
+}
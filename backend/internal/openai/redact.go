@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/redact"
+)
+
+// SetRedactor sets the redactor applied to everything sent to the provider.
+// nil disables redaction.
+func (s *Session) SetRedactor(r *redact.Redactor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redactor = r
+}
+
+func (s *Session) currentRedactor() *redact.Redactor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.redactor
+}
+
+// redactText removes secrets from text bound for the provider and records
+// what was removed in the audit trail
+func (s *Session) redactText(id int64, source, text string) string {
+	r := s.currentRedactor()
+	redacted, findings := r.Text(text)
+	if len(findings) == 0 {
+		return redacted
+	}
+
+	var kinds []string
+	for _, f := range findings {
+		kinds = append(kinds, fmt.Sprintf("%s×%d", f.Detector, f.Count))
+	}
+	fmt.Printf("🙈 Redacted from %s: %s\n", source, strings.Join(kinds, ", "))
+	if err := r.Log(id, source, findings); err != nil {
+		fmt.Printf("Warning: failed to write redaction audit: %v\n", err)
+	}
+	return redacted
+}
+
+// maskScreenshot returns the path of a copy of the screenshot with the
+// configured regions blacked out, or path itself when nothing is masked.
+// Failing to mask is an error so the unmasked image is never uploaded.
+func (s *Session) maskScreenshot(id int64, path string) (string, error) {
+	r := s.currentRedactor()
+	if !r.HasMasks() {
+		return path, nil
+	}
+
+	masked, applied, err := r.MaskImage(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to mask screenshot: %w", err)
+	}
+	if err := r.LogMasks(id, path, applied); err != nil {
+		fmt.Printf("Warning: failed to write redaction audit: %v\n", err)
+	}
+	return masked, nil
+}
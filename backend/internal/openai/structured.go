@@ -0,0 +1,28 @@
+package openai
+
+import (
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/stream"
+	"github.com/PeterShin23/MyAssistant/backend/internal/structured"
+)
+
+// deliverStructured validates a structured answer and sends it to the writer
+// as a structured event with its markdown rendering. An answer that fails
+// validation is passed through as a plain delta so it isn't lost.
+func (s *Session) deliverStructured(id int64, cfg *config.StructuredConfig, content string) {
+	data, value, err := structured.Parse(cfg.Schema, content)
+	if err != nil {
+		fmt.Printf("Warning: structured answer does not match the %s schema: %v\n", cfg.Name, err)
+		s.emit(stream.Event{Type: stream.EventDelta, RequestID: id, Text: content})
+		return
+	}
+
+	s.emit(stream.Event{
+		Type:      stream.EventStructured,
+		RequestID: id,
+		Text:      structured.Render(cfg.Schema, value),
+		Data:      data,
+	})
+}
@@ -0,0 +1,29 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+	"github.com/PeterShin23/MyAssistant/backend/internal/redact"
+	"github.com/PeterShin23/MyAssistant/backend/internal/tools"
+)
+
+// SetTools offers the registry's tools to the model on every turn
+func (s *Session) SetTools(reg *tools.Registry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = reg
+}
+
+// runToolCalls executes the calls the model requested and returns the
+// assistant and tool messages that record them in the conversation
+func (s *Session) runToolCalls(ctx context.Context, id int64, resp *llm.ChatResponse) []llm.Message {
+	messages := []llm.Message{llm.AssistantToolCallMessage(resp.Content, resp.ToolCalls)}
+	for _, call := range resp.ToolCalls {
+		fmt.Printf("🛠️  Tool %s(%s)\n", call.Name, call.Arguments)
+		result := s.redactText(id, redact.SourceTool, s.tools.Call(ctx, call))
+		messages = append(messages, llm.ToolMessage(call.ID, result))
+	}
+	return messages
+}
@@ -0,0 +1,34 @@
+package openai
+
+import (
+	"context"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/transcribe"
+)
+
+// SetTranscriber sets the speech-to-text backend. Without one, audio goes to
+// the provider with the transcription settings from rules.json.
+func (s *Session) SetTranscriber(t transcribe.Transcriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transcriber = t
+}
+
+func (s *Session) transcribeAudio(ctx context.Context, audioPath string) (string, error) {
+	if audioPath == "" {
+		return "", nil
+	}
+
+	s.mu.Lock()
+	t := s.transcriber
+	if t == nil {
+		t = transcribe.FromProvider(s.base.Transcription, s.provider)
+	}
+	s.mu.Unlock()
+
+	result, err := t.Transcribe(ctx, audioPath)
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
@@ -0,0 +1,11 @@
+package openai
+
+import "github.com/PeterShin23/MyAssistant/backend/internal/usage"
+
+// SetUsage records the usage of every provider request in store, tagged
+// with the profile the request was sent with. Call it before processing starts.
+func (s *Session) SetUsage(store *usage.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.provider = usage.Meter(s.provider, store, s.Profile)
+}
@@ -0,0 +1,214 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/screen"
+)
+
+const FOLDER_PERMS_READALL_WRITEOWN = 0755
+
+// Dir holds captures that could not be sent, one directory per capture with
+// copies of its files and an item.json describing it
+var Dir = filepath.Join(".data", "queue")
+
+// itemFile is the metadata file inside a capture's directory
+const itemFile = "item.json"
+
+// Item is a queued capture
+type Item struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+	// Profile is the profile that was active when the capture was taken
+	Profile string `json:"profile,omitempty"`
+	// Screenshots and AudioPath point at the copies inside the item's directory
+	Screenshots []screen.Screenshot `json:"screenshots,omitempty"`
+	AudioPath   string              `json:"audioPath,omitempty"`
+	// Transcript is set when the audio was already transcribed
+	Transcript string `json:"transcript,omitempty"`
+	// Text is a typed question
+	Text string `json:"text,omitempty"`
+
+	Attempts    int       `json:"attempts"`
+	LastAttempt time.Time `json:"lastAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// Preview is a one-line summary of what was asked
+func (it Item) Preview() string {
+	switch {
+	case it.Text != "":
+		return it.Text
+	case it.Transcript != "":
+		return it.Transcript
+	case it.AudioPath != "":
+		return "(untranscribed audio)"
+	default:
+		return fmt.Sprintf("(%d screenshots)", len(it.Screenshots))
+	}
+}
+
+// Add stores a capture, copying its screenshots and audio into the queue so
+// clearing or rotating .data doesn't lose them. It returns the stored item.
+func Add(item Item) (*Item, error) {
+	if err := os.MkdirAll(Dir, FOLDER_PERMS_READALL_WRITEOWN); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+
+	// Captures can fail in quick succession, so the ID may need a suffix
+	base := item.Created.Format("20060102-150405.000")
+	item.ID = base
+	for n := 2; ; n++ {
+		err := os.Mkdir(itemDir(item.ID), FOLDER_PERMS_READALL_WRITEOWN)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		item.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	dir := itemDir(item.ID)
+	fail := func(err error) (*Item, error) {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	shots := make([]screen.Screenshot, 0, len(item.Screenshots))
+	for i, shot := range item.Screenshots {
+		path, err := copyInto(dir, fmt.Sprintf("%d-", i), shot.Path)
+		if err != nil {
+			return fail(fmt.Errorf("failed to queue screenshot: %w", err))
+		}
+		shots = append(shots, screen.Screenshot{Path: path, Label: shot.Label})
+	}
+	item.Screenshots = shots
+
+	if item.AudioPath != "" {
+		path, err := copyInto(dir, "audio-", item.AudioPath)
+		if err != nil {
+			return fail(fmt.Errorf("failed to queue audio: %w", err))
+		}
+		item.AudioPath = path
+	}
+
+	if err := Update(item); err != nil {
+		return fail(err)
+	}
+	return &item, nil
+}
+
+// Update rewrites an item's metadata, e.g. after a failed attempt
+func Update(item Item) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a crash never leaves a torn item.json
+	path := filepath.Join(itemDir(item.ID), itemFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// List returns the queued captures, oldest first. A missing queue yields none.
+func List() ([]Item, error) {
+	entries, err := os.ReadDir(Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var items []Item
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		item, err := Get(entry.Name())
+		if err != nil {
+			fmt.Printf("Warning: skipping queued capture %s: %v\n", entry.Name(), err)
+			continue
+		}
+		items = append(items, *item)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Created.Before(items[j].Created) })
+	return items, nil
+}
+
+// Get loads a queued capture by ID
+func Get(id string) (*Item, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(itemDir(id), itemFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no queued capture %q", id)
+		}
+		return nil, err
+	}
+
+	var item Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to read queued capture %s: %w", id, err)
+	}
+	return &item, nil
+}
+
+// Drop deletes a queued capture and its files
+func Drop(id string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	if _, err := os.Stat(itemDir(id)); err != nil {
+		return fmt.Errorf("no queued capture %q", id)
+	}
+	return os.RemoveAll(itemDir(id))
+}
+
+func checkID(id string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid queue id %q", id)
+	}
+	return nil
+}
+
+func itemDir(id string) string {
+	return filepath.Join(Dir, id)
+}
+
+// copyInto copies a file into dir and returns the new path. The name is the
+// original one behind prefix, so inputs with the same name don't collide.
+func copyInto(dir, prefix, path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	target := filepath.Join(dir, prefix+filepath.Base(path))
+	dst, err := os.Create(target)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", err
+	}
+	return target, dst.Close()
+}
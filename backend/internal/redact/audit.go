@@ -0,0 +1,94 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/imageproc"
+)
+
+const FOLDER_PERMS_READALL_WRITEOWN = 0755
+
+// AuditPath is the redaction audit trail, one JSON record per redaction.
+// It records what kind of data was removed and where, never the data itself.
+var AuditPath = filepath.Join(".data", "redaction", "audit.jsonl")
+
+// AuditRecord describes what was redacted from one part of a request
+type AuditRecord struct {
+	Time      time.Time `json:"time"`
+	RequestID int64     `json:"requestId"`
+	Source    string    `json:"source"`
+	Detector  string    `json:"detector,omitempty"`
+	Count     int       `json:"count,omitempty"`
+	// File and Masks are set for masked screenshots
+	File  string           `json:"file,omitempty"`
+	Masks []imageproc.Rect `json:"masks,omitempty"`
+}
+
+// Audit appends records to AuditPath
+type Audit struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAudit returns an audit trail writing to AuditPath
+func NewAudit() *Audit {
+	return &Audit{path: AuditPath}
+}
+
+// Append adds records to the audit trail
+func (a *Audit) Append(records ...AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(a.path), FOLDER_PERMS_READALL_WRITEOWN); err != nil {
+		return fmt.Errorf("failed to create redaction dir: %w", err)
+	}
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := time.Now()
+	for _, record := range records {
+		record.Time = now
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Log records the findings of a redacted text
+func (r *Redactor) Log(requestID int64, source string, findings []Finding) error {
+	if r == nil {
+		return nil
+	}
+	var records []AuditRecord
+	for _, f := range findings {
+		records = append(records, AuditRecord{RequestID: requestID, Source: source, Detector: f.Detector, Count: f.Count})
+	}
+	return r.audit.Append(records...)
+}
+
+// LogMasks records the rectangles masked in a screenshot
+func (r *Redactor) LogMasks(requestID int64, file string, masks []imageproc.Rect) error {
+	if r == nil || len(masks) == 0 {
+		return nil
+	}
+	return r.audit.Append(AuditRecord{RequestID: requestID, Source: SourceImage, File: file, Masks: masks, Count: len(masks)})
+}
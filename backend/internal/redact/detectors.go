@@ -0,0 +1,60 @@
+package redact
+
+import (
+	"regexp"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// builtins are the detectors behind each name in config.RedactionDetectors
+var builtins = map[string][]detector{
+	config.RedactAPIKeys: {
+		newDetector("private-key", `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+		newDetector("openai-key", `\bsk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,}`),
+		newDetector("aws-access-key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+		newDetector("github-token", `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+		newDetector("slack-token", `\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+		newDetector("google-api-key", `\bAIza[0-9A-Za-z_-]{35}\b`),
+		newDetector("stripe-key", `\b[rs]k_(?:live|test)_[0-9A-Za-z]{16,}\b`),
+		newDetector("jwt", `\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`),
+		// Values assigned to obviously secret names, e.g. API_KEY=... or "password": "...".
+		// Values already replaced above start with "[" and are skipped.
+		newDetector("secret-assignment", `(?i)(?:api[_-]?key|secret|token|password|passwd)["']?\s*[:=]\s*["']?(?P<secret>[^\s"',;\[][^\s"',;]{7,})`),
+	},
+	config.RedactEmails: {
+		newDetector("email", `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	},
+	config.RedactCards: {
+		{
+			name:        "card",
+			pattern:     regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+			replacement: placeholder("card"),
+			valid:       luhn,
+		},
+	},
+}
+
+func newDetector(name, pattern string) detector {
+	return detector{name: name, pattern: regexp.MustCompile(pattern), replacement: placeholder(name)}
+}
+
+// luhn reports whether the digits in s have a valid card checksum
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // screenshots may be JPEG
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/imageproc"
+)
+
+// maskedSuffix marks the redacted copy of a screenshot
+const maskedSuffix = ".masked.png"
+
+// HasMasks reports whether screenshots need masking
+func (r *Redactor) HasMasks() bool {
+	return r != nil && len(r.masks) > 0
+}
+
+// MaskImage blacks out the configured rectangles and writes the result next
+// to the screenshot. It returns the path of the masked copy and the
+// rectangles that overlapped the image, or path itself when there are no masks
+// or it is already a masked copy, e.g. a queued capture.
+func (r *Redactor) MaskImage(path string) (string, []imageproc.Rect, error) {
+	if !r.HasMasks() || strings.HasSuffix(path, maskedSuffix) {
+		return path, nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	bounds := src.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), src, bounds.Min, draw.Src)
+
+	var applied []imageproc.Rect
+	black := image.NewUniform(color.Black)
+	for _, mask := range r.masks {
+		region := image.Rect(mask.X, mask.Y, mask.X+mask.Width, mask.Y+mask.Height).Intersect(out.Bounds())
+		if region.Empty() {
+			continue
+		}
+		draw.Draw(out, region, black, image.Point{}, draw.Src)
+		applied = append(applied, mask)
+	}
+
+	masked := strings.TrimSuffix(path, filepath.Ext(path)) + maskedSuffix
+	file, err := os.Create(masked)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := png.Encode(file, out); err != nil {
+		file.Close()
+		return "", nil, err
+	}
+	if err := file.Close(); err != nil {
+		return "", nil, err
+	}
+	return masked, applied, nil
+}
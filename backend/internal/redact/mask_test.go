@@ -0,0 +1,163 @@
+package redact
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/imageproc"
+)
+
+var white = color.RGBA{255, 255, 255, 255}
+
+// writeImage writes a white PNG of the given size and returns its path
+func writeImage(t *testing.T, w, h int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, white)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "screen.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readImage(t *testing.T, path string) image.Image {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func isBlack(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r == 0 && g == 0 && b == 0
+}
+
+func TestMaskImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		masks   []imageproc.Rect
+		applied []imageproc.Rect
+		// black and kept are sample pixels expected to be masked and untouched
+		black []image.Point
+		kept  []image.Point
+	}{
+		{
+			name:    "inside",
+			masks:   []imageproc.Rect{{X: 10, Y: 10, Width: 20, Height: 5}},
+			applied: []imageproc.Rect{{X: 10, Y: 10, Width: 20, Height: 5}},
+			black:   []image.Point{{10, 10}, {29, 14}, {20, 12}},
+			kept:    []image.Point{{9, 10}, {30, 14}, {10, 15}, {0, 0}},
+		},
+		{
+			name:    "clipped at the edge",
+			masks:   []imageproc.Rect{{X: 90, Y: 40, Width: 50, Height: 50}},
+			applied: []imageproc.Rect{{X: 90, Y: 40, Width: 50, Height: 50}},
+			black:   []image.Point{{90, 40}, {99, 49}},
+			kept:    []image.Point{{89, 40}, {90, 39}},
+		},
+		{
+			name:  "entirely outside",
+			masks: []imageproc.Rect{{X: 200, Y: 200, Width: 10, Height: 10}},
+			kept:  []image.Point{{0, 0}, {99, 49}},
+		},
+		{
+			name: "several",
+			masks: []imageproc.Rect{
+				{X: 0, Y: 0, Width: 5, Height: 5},
+				{X: 500, Y: 0, Width: 5, Height: 5},
+				{X: 50, Y: 20, Width: 5, Height: 5},
+			},
+			applied: []imageproc.Rect{
+				{X: 0, Y: 0, Width: 5, Height: 5},
+				{X: 50, Y: 20, Width: 5, Height: 5},
+			},
+			black: []image.Point{{0, 0}, {4, 4}, {50, 20}, {54, 24}},
+			kept:  []image.Point{{5, 5}, {49, 20}, {99, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRedactor(t, config.RedactionConfig{Masks: tt.masks})
+			path := writeImage(t, 100, 50)
+
+			masked, applied, err := r.MaskImage(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if masked == path {
+				t.Fatal("MaskImage returned the original path")
+			}
+			if !reflect.DeepEqual(applied, tt.applied) {
+				t.Errorf("applied = %+v, want %+v", applied, tt.applied)
+			}
+
+			img := readImage(t, masked)
+			if got := img.Bounds(); got != image.Rect(0, 0, 100, 50) {
+				t.Errorf("bounds = %v, want the original size", got)
+			}
+			for _, p := range tt.black {
+				if !isBlack(img.At(p.X, p.Y)) {
+					t.Errorf("pixel %v = %v, want black", p, img.At(p.X, p.Y))
+				}
+			}
+			for _, p := range tt.kept {
+				if isBlack(img.At(p.X, p.Y)) {
+					t.Errorf("pixel %v was masked", p)
+				}
+			}
+
+			// The original screenshot is left as it was
+			if isBlack(readImage(t, path).At(tt.masks[0].X%100, tt.masks[0].Y%50)) {
+				t.Error("the original screenshot was modified")
+			}
+		})
+	}
+}
+
+func TestMaskImageSkipsMaskedCopies(t *testing.T) {
+	r := newRedactor(t, config.RedactionConfig{Masks: []imageproc.Rect{{X: 0, Y: 0, Width: 5, Height: 5}}})
+	path := writeImage(t, 10, 10)
+
+	masked, _, err := r.MaskImage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, applied, err := r.MaskImage(masked)
+	if err != nil || again != masked || applied != nil {
+		t.Errorf("MaskImage(masked copy) = %q, %v, %v; want it returned as is", again, applied, err)
+	}
+}
+
+func TestMaskImageWithoutMasks(t *testing.T) {
+	r := newRedactor(t, config.RedactionConfig{})
+	path := writeImage(t, 10, 10)
+
+	masked, applied, err := r.MaskImage(path)
+	if err != nil || masked != path || applied != nil {
+		t.Errorf("MaskImage = %q, %v, %v; want the original path", masked, applied, err)
+	}
+}
@@ -0,0 +1,137 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/imageproc"
+)
+
+// Where redacted content came from, as recorded in the audit log
+const (
+	SourceTranscript = "transcript"
+	SourceScreenText = "ocr"
+	SourceText       = "text"
+	SourceTool       = "tool"
+	SourceImage      = "image"
+)
+
+// Finding counts the matches of one detector or rule in a piece of text
+type Finding struct {
+	Detector string
+	Count    int
+}
+
+// detector replaces the matches of pattern. When the pattern has a group
+// named "secret", only that group is replaced. valid, if set, rejects false
+// positives such as digit runs that fail the card checksum.
+type detector struct {
+	name        string
+	pattern     *regexp.Regexp
+	replacement string
+	valid       func(match string) bool
+}
+
+// Redactor removes secrets and personal data from text and masks regions of
+// screenshots. A nil Redactor leaves everything unchanged.
+type Redactor struct {
+	detectors []detector
+	masks     []imageproc.Rect
+	audit     *Audit
+}
+
+// New builds a redactor from the config. It returns nil when redaction is off.
+func New(cfg config.RedactionConfig) (*Redactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	r := &Redactor{masks: cfg.Masks, audit: NewAudit()}
+	for _, name := range cfg.Detectors {
+		r.detectors = append(r.detectors, builtins[name]...)
+	}
+	for _, rule := range cfg.Rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %q: %w", rule.Name, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = placeholder(rule.Name)
+		}
+		r.detectors = append(r.detectors, detector{name: rule.Name, pattern: pattern, replacement: replacement})
+	}
+	return r, nil
+}
+
+// Describe summarizes the configuration for the startup banner
+func (r *Redactor) Describe() string {
+	if r == nil {
+		return "off"
+	}
+	names := map[string]bool{}
+	for _, d := range r.detectors {
+		names[d.name] = true
+	}
+	return fmt.Sprintf("%d detectors, %d masks", len(names), len(r.masks))
+}
+
+// Text redacts text and reports what was found, sorted by detector name
+func (r *Redactor) Text(text string) (string, []Finding) {
+	if r == nil || text == "" {
+		return text, nil
+	}
+
+	counts := map[string]int{}
+	for _, d := range r.detectors {
+		var n int
+		text, n = d.replace(text)
+		if n > 0 {
+			counts[d.name] += n
+		}
+	}
+
+	var findings []Finding
+	for name, n := range counts {
+		findings = append(findings, Finding{Detector: name, Count: n})
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Detector < findings[j].Detector })
+	return text, findings
+}
+
+// replace substitutes every valid match in text and returns the number replaced
+func (d detector) replace(text string) (string, int) {
+	group := d.pattern.SubexpIndex("secret")
+
+	var out strings.Builder
+	last, n := 0, 0
+	for _, loc := range d.pattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if group > 0 {
+			start, end = loc[2*group], loc[2*group+1]
+			if start < 0 {
+				continue
+			}
+		}
+		if d.valid != nil && !d.valid(text[start:end]) {
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(d.replacement)
+		last = end
+		n++
+	}
+	if n == 0 {
+		return text, 0
+	}
+	out.WriteString(text[last:])
+	return out.String(), n
+}
+
+// placeholder is the default replacement for a detector
+func placeholder(name string) string {
+	return "[REDACTED:" + name + "]"
+}
@@ -1,48 +1,110 @@
 package screen
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
 )
 
 const FOLDER_PERMS_READALL_WRITEOWN = 0755
 
-type DisplayConfig struct {
-	Display int `json:"display"`
+// Screenshot is one captured image and the label shown to the model
+type Screenshot struct {
+	Path  string `json:"path"`
+	Label string `json:"label,omitempty"`
 }
 
-func CaptureScreenshot() (string, error) {
-	// GO uses arbitrarily but tastifully chosen values for datetime formatting
-	now := time.Now().Format("2006-01-02T15:04:05.000")
+var (
+	previousMu sync.Mutex
+	previous   []Screenshot // last set of captures, for includePrevious
+)
+
+// CaptureScreenshots captures every display listed in rules.json, skipping
+// displays that fail (e.g. an unplugged monitor) as long as one succeeds. With
+// includePrevious set, the previous turn's captures are returned first so the
+// model can compare before and after.
+func CaptureScreenshots() ([]Screenshot, error) {
+	rules := loadRules()
+	displays := rules.CaptureDisplays()
+	multi := len(displays) > 1
+
+	// One timestamp for the whole set so the files sort together
+	now := timestamp()
+
+	var current []Screenshot
+	var errs []error
+	for _, display := range displays {
+		path, err := captureDisplay(display, now, multi)
+		if err != nil {
+			if multi {
+				fmt.Printf("Warning: skipping display %d: %v\n", display, err)
+			}
+			errs = append(errs, fmt.Errorf("display %d: %w", display, err))
+			continue
+		}
+		current = append(current, Screenshot{Path: path, Label: fmt.Sprintf("Display %d", display)})
+	}
+	if len(current) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	previousMu.Lock()
+	before := previous
+	previous = current
+	previousMu.Unlock()
+
+	if !rules.IncludePrevious || len(before) == 0 {
+		return current, nil
+	}
+
+	shots := make([]Screenshot, 0, len(before)+len(current))
+	for _, shot := range before {
+		shots = append(shots, Screenshot{Path: shot.Path, Label: "Previous capture, " + shot.Label})
+	}
+	for _, shot := range current {
+		shots = append(shots, Screenshot{Path: shot.Path, Label: "Current capture, " + shot.Label})
+	}
+	return shots, nil
+}
+
+func captureDisplay(display int, now string, multi bool) (string, error) {
 	outputPath := fmt.Sprintf(".data/%s.jpg", now)
+	if multi {
+		outputPath = fmt.Sprintf(".data/%s-d%d.jpg", now, display)
+	}
 
 	// Ensure .data directory exists
 	if err := os.MkdirAll(filepath.Dir(outputPath), FOLDER_PERMS_READALL_WRITEOWN); err != nil {
 		return "", fmt.Errorf("failed to create .data directory: %w", err)
 	}
 
-	// Default to display 1
-	display := 1
-
-	// Try to read from rules.json
-	rulesBytes, err := os.ReadFile("rules.json")
-	if err == nil {
-		var cfg DisplayConfig
-		if err := json.Unmarshal(rulesBytes, &cfg); err == nil && cfg.Display > 0 {
-			display = cfg.Display
-		}
-	}
-
 	// Use macOS's built-in screenshot command
 	cmd := exec.Command("screencapture", "-x", "-D", fmt.Sprintf("%d", display), outputPath)
 
 	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to run screencapture: %w", err)
+		return "", fmt.Errorf("failed to run screencapture (display %d): %w", display, err)
 	}
 
 	return outputPath, nil
 }
+
+// loadRules reads rules.json on every capture so display changes apply without a restart
+func loadRules() *config.Rules {
+	rules, err := config.Load()
+	if err != nil {
+		fmt.Printf("Warning: %v (capturing display 1)\n", err)
+		return &config.Rules{}
+	}
+	return rules
+}
+
+func timestamp() string {
+	// GO uses arbitrarily but tastifully chosen values for datetime formatting
+	return time.Now().Format("2006-01-02T15:04:05.000")
+}
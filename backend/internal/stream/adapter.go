@@ -0,0 +1,49 @@
+package stream
+
+import "fmt"
+
+// chunkAdapter turns events into calls on a ChunkWriter
+type chunkAdapter struct {
+	w ChunkWriter
+}
+
+// FromChunkWriter adapts a chunk-based writer to the event interface. Answer
+// text and markers become chunks and every terminal event marks the stream
+// complete; events without text, such as usage, are dropped.
+func FromChunkWriter(w ChunkWriter) StreamWriter {
+	return &chunkAdapter{w: w}
+}
+
+// WriteEvent implements StreamWriter
+func (a *chunkAdapter) WriteEvent(ev Event) error {
+	var chunk string
+	switch ev.Type {
+	case EventStarted:
+		if ev.Late {
+			chunk = fmt.Sprintf(lateMarker, ev.Text)
+		}
+	case EventDelta, EventStructured:
+		chunk = ev.Text
+	case EventCached:
+		chunk = cachedMarker
+	case EventCancelled:
+		chunk = cancelledMarker
+	case EventError:
+		chunk = fmt.Sprintf(errorMarker, ev.Text)
+	}
+
+	if chunk != "" {
+		if err := a.w.WriteChunk(chunk); err != nil {
+			return err
+		}
+	}
+	if ev.Terminal() {
+		return a.w.MarkStreamComplete()
+	}
+	return nil
+}
+
+// Close implements StreamWriter
+func (a *chunkAdapter) Close() error {
+	return a.w.Close()
+}
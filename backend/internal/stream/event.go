@@ -0,0 +1,62 @@
+package stream
+
+import "encoding/json"
+
+// EventType identifies what happened during a request
+type EventType string
+
+// Event types, in the order a request produces them. Every request starts
+// with EventStarted and ends with exactly one of EventCompleted,
+// EventCancelled or EventError.
+const (
+	// EventStarted opens a request; Profile is set when one is active, and
+	// Late when it answers a queued capture
+	EventStarted EventType = "started"
+	// EventPartialTranscript carries the transcript so far in Text, while still recording
+	EventPartialTranscript EventType = "partial-transcript"
+	// EventTranscript carries the transcribed audio in Text
+	EventTranscript EventType = "transcript"
+	// EventCached precedes an answer replayed from the cache
+	EventCached EventType = "cached"
+	// EventDelta carries the next piece of the answer in Text
+	EventDelta EventType = "delta"
+	// EventStructured carries a validated structured answer in Data, rendered as markdown in Text
+	EventStructured EventType = "structured"
+	// EventUsage reports the tokens the request used
+	EventUsage EventType = "usage"
+	// EventCompleted closes a request that finished normally
+	EventCompleted EventType = "completed"
+	// EventCancelled closes a request the user stopped
+	EventCancelled EventType = "cancelled"
+	// EventError closes a request that failed; Text is the error message
+	EventError EventType = "error"
+)
+
+// Usage is the token usage reported with EventUsage
+type Usage struct {
+	PromptTokens     int64 `json:"promptTokens"`
+	CompletionTokens int64 `json:"completionTokens"`
+	CachedTokens     int64 `json:"cachedTokens,omitempty"`
+}
+
+// Event is something that happened during a request
+type Event struct {
+	Type EventType
+	// RequestID ties the events of one request together
+	RequestID int64
+	Text      string
+	Profile   string
+	Data      json.RawMessage
+	Usage     *Usage
+	// Late marks the answer to a capture that was queued while offline;
+	// Text on EventStarted then says when it was taken
+	Late bool
+	// FollowUp marks a typed question about the conversation, sent without a
+	// new capture
+	FollowUp bool
+}
+
+// Terminal reports whether the event ends its request
+func (e Event) Terminal() bool {
+	return e.Type == EventCompleted || e.Type == EventCancelled || e.Type == EventError
+}
@@ -1,7 +1,28 @@
 package stream
 
-// StreamWriter defines the interface for writing streaming chunks
+// cancelledMarker is appended to the output of a cancelled stream
+const cancelledMarker = "\n\n_⏹️ Cancelled_\n"
+
+// cachedMarker precedes an answer replayed from the cache
+const cachedMarker = "_♻️ Cached answer_\n\n"
+
+// lateMarker precedes the answer to a capture that was queued while offline
+const lateMarker = "_⏰ %s_\n\n"
+
+// errorMarker is appended to the output of a failed request
+const errorMarker = "\n\n_❌ %s_\n"
+
+// StreamWriter receives the typed events of every request
 type StreamWriter interface {
+	// WriteEvent delivers a single event
+	WriteEvent(ev Event) error
+	// Close closes the stream and releases any resources (should only be called on terminal shutdown)
+	Close() error
+}
+
+// ChunkWriter is the original chunk-based writer interface. Wrap one with
+// FromChunkWriter to use it where a StreamWriter is expected.
+type ChunkWriter interface {
 	// WriteChunk writes a chunk of content to the stream
 	WriteChunk(chunk string) error
 	// MarkStreamComplete marks the current stream as complete without closing the connection
@@ -0,0 +1,121 @@
+package stream
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// sentenceSplitter cuts streamed markdown into speakable sentences and drops
+// fenced code blocks, which make no sense read aloud
+type sentenceSplitter struct {
+	line    string // the part of the current line not yet split
+	midLine bool   // line doesn't start at the beginning of a line
+	inCode  bool
+}
+
+// Write adds streamed text and returns the sentences it completes
+func (sp *sentenceSplitter) Write(text string) []string {
+	sp.line += text
+
+	var sentences []string
+	for {
+		i := strings.IndexByte(sp.line, '\n')
+		if i < 0 {
+			break
+		}
+		sentences = append(sentences, sp.endLine(sp.line[:i])...)
+		sp.line = sp.line[i+1:]
+	}
+
+	// Speak what is already complete in the line still streaming in, unless
+	// it could turn out to be a code fence
+	if sp.inCode || sp.maybeFence() {
+		return sentences
+	}
+	done, rest := splitSentences(sp.line)
+	if len(done) > 0 {
+		sentences = append(sentences, done...)
+		sp.line = rest
+		sp.midLine = true
+	}
+	return sentences
+}
+
+// Flush returns whatever is left at the end of an answer
+func (sp *sentenceSplitter) Flush() []string {
+	sentences := sp.endLine(sp.line)
+	*sp = sentenceSplitter{}
+	return sentences
+}
+
+// endLine finishes the current line. Every line ends a sentence, since
+// markdown answers put headings, list items and paragraphs on their own lines.
+func (sp *sentenceSplitter) endLine(line string) []string {
+	defer func() { sp.midLine = false }()
+
+	if !sp.midLine && strings.HasPrefix(strings.TrimSpace(line), "```") {
+		sp.inCode = !sp.inCode
+		return nil
+	}
+	if sp.inCode {
+		return nil
+	}
+
+	sentences, rest := splitSentences(line)
+	if text := speakable(rest); text != "" {
+		sentences = append(sentences, text)
+	}
+	return sentences
+}
+
+// maybeFence reports whether the current line could still become a code fence
+func (sp *sentenceSplitter) maybeFence() bool {
+	if sp.midLine {
+		return false
+	}
+	start := strings.TrimLeft(sp.line, " \t")
+	return strings.HasPrefix(start, "```") || strings.HasPrefix("```", start)
+}
+
+// splitSentences cuts text after every ".", "!" or "?" followed by a space
+// and returns the speakable sentences and the unfinished rest
+func splitSentences(text string) ([]string, string) {
+	var sentences []string
+	start := 0
+	for i, r := range text {
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		next := i + 1
+		if next >= len(text) || !unicode.IsSpace(rune(text[next])) {
+			continue
+		}
+		if sentence := speakable(text[start:next]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = next
+	}
+	return sentences, text[start:]
+}
+
+var (
+	markdownLink   = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownPrefix = regexp.MustCompile(`^\s*(#{1,6}\s+|>\s*|[-*+]\s+|\d+[.)]\s+)`)
+)
+
+// speakable strips markdown syntax that would otherwise be read out
+func speakable(text string) string {
+	text = markdownPrefix.ReplaceAllString(text, "")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = strings.NewReplacer("`", "", "**", "", "__", "", "*", "").Replace(text)
+	text = strings.TrimSpace(text)
+
+	// Skip leftovers without any words, such as table borders
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return text
+		}
+	}
+	return ""
+}
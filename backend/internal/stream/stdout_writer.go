@@ -3,33 +3,46 @@ package stream
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/glamour"
 )
 
 // StdoutWriter implements StreamWriter for writing to stdout
 type StdoutWriter struct {
-	pretty      bool
-	fullContent string
+	pretty bool
+	mu     sync.Mutex
+	// contents accumulates each request's answer in pretty mode, keyed by
+	// request ID (0 for plain WriteChunk calls)
+	contents map[int64]string
 }
 
 // NewStdoutWriter creates a new StdoutWriter
 func NewStdoutWriter(pretty bool) *StdoutWriter {
 	return &StdoutWriter{
-		pretty: pretty,
+		pretty:   pretty,
+		contents: make(map[int64]string),
 	}
 }
 
 // WriteChunk writes a chunk to stdout
 func (w *StdoutWriter) WriteChunk(chunk string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.write(0, chunk)
+}
+
+// write prints a chunk of a request's answer. Caller must hold w.mu.
+func (w *StdoutWriter) write(id int64, chunk string) error {
 	if !w.pretty {
 		// For raw mode, write chunks as they arrive
 		_, err := fmt.Fprint(os.Stdout, chunk)
 		return err
 	}
 	// For pretty mode, accumulate the full content
-	w.fullContent += chunk
+	w.contents[id] += chunk
 	return nil
 }
 
@@ -40,6 +53,45 @@ func (w *StdoutWriter) MarkStreamComplete() error {
 	return nil
 }
 
+// WriteEvent implements StreamWriter. The processor already logs request
+// starts, transcripts and usage, so only answer text and markers are printed.
+func (w *StdoutWriter) WriteEvent(ev Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := ev.RequestID
+	switch ev.Type {
+	case EventStarted:
+		// A new request starts a new answer
+		delete(w.contents, id)
+		if ev.Late {
+			return w.write(id, fmt.Sprintf(lateMarker, ev.Text))
+		}
+		return nil
+	case EventDelta, EventStructured:
+		return w.write(id, ev.Text)
+	case EventCached:
+		return w.write(id, cachedMarker)
+	case EventCancelled:
+		// An explicit marker so a cut-off answer isn't mistaken for a complete one
+		if err := w.write(id, cancelledMarker); err != nil {
+			return err
+		}
+	case EventError:
+		if err := w.write(id, fmt.Sprintf(errorMarker, ev.Text)); err != nil {
+			return err
+		}
+	}
+
+	if ev.Terminal() && w.pretty {
+		// Render each answer as soon as it ends
+		content := w.contents[id]
+		delete(w.contents, id)
+		return render(content)
+	}
+	return nil
+}
+
 // Close implements StreamWriter
 func (w *StdoutWriter) Close() error {
 	if !w.pretty {
@@ -47,11 +99,33 @@ func (w *StdoutWriter) Close() error {
 		_, err := fmt.Fprintln(os.Stdout)
 		return err
 	}
-	
-	// For pretty mode, render the full content with markdown
-	if w.fullContent != "" {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Render whatever is unfinished, oldest request first
+	ids := make([]int64, 0, len(w.contents))
+	for id := range w.contents {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) == 0 {
+		return render("")
+	}
+	for _, id := range ids {
+		if err := render(w.contents[id]); err != nil {
+			return err
+		}
+	}
+	w.contents = make(map[int64]string)
+	return nil
+}
+
+// render prints an answer as markdown
+func render(content string) error {
+	if content != "" {
 		// Clean up common streaming artifacts
-		cleanedContent := strings.TrimSpace(w.fullContent)
+		cleanedContent := strings.TrimSpace(content)
 		if cleanedContent != "" {
 			formatted, err := renderMarkdown(cleanedContent)
 			if err != nil {
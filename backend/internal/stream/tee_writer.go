@@ -19,8 +19,8 @@ func NewTeeWriter(writers ...StreamWriter) *TeeWriter {
 	}
 }
 
-// WriteChunk writes a chunk to all underlying writers
-func (t *TeeWriter) WriteChunk(chunk string) error {
+// WriteEvent delivers an event to all underlying writers
+func (t *TeeWriter) WriteEvent(ev Event) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	
@@ -28,42 +28,14 @@ func (t *TeeWriter) WriteChunk(chunk string) error {
 		return nil // Don't write to closed writers
 	}
 	
-	// fmt.Printf("[TeeWriter] Writing chunk to %d writers (length: %d)\n", len(t.writers), len(chunk))
-	
 	// Write to all writers, collecting errors
 	var firstErr error
 	for i, writer := range t.writers {
-		if err := writer.WriteChunk(chunk); err != nil {
-			fmt.Printf("[TeeWriter] Writer %d failed: %v\n", i, err)
-			if firstErr == nil {
-				firstErr = err
-			}
-		} else {
-			// fmt.Printf("[TeeWriter] Writer %d succeeded\n", i)
-		}
-	}
-	return firstErr
-}
-
-// MarkStreamComplete marks the current stream as complete for all underlying writers
-func (t *TeeWriter) MarkStreamComplete() error {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-	
-	if t.closed {
-		return nil
-	}
-	
-	// Mark stream complete on all writers, collecting errors
-	var firstErr error
-	for i, writer := range t.writers {
-		if err := writer.MarkStreamComplete(); err != nil {
-			fmt.Printf("[TeeWriter] Writer %d MarkStreamComplete failed: %v\n", i, err)
+		if err := writer.WriteEvent(ev); err != nil {
+			fmt.Printf("[TeeWriter] Writer %d failed on %s event: %v\n", i, ev.Type, err)
 			if firstErr == nil {
 				firstErr = err
 			}
-		} else {
-			// fmt.Printf("[TeeWriter] Writer %d MarkStreamComplete succeeded\n", i)
 		}
 	}
 	return firstErr
@@ -0,0 +1,210 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Speaker says text aloud; see the tts package for implementations
+type Speaker interface {
+	// Name identifies the speaker in logs
+	Name() string
+	// Speak says text and returns when it has been spoken. Cancelling ctx
+	// stops playback.
+	Speak(ctx context.Context, text string) error
+}
+
+// TTSWriter implements StreamWriter by speaking answers sentence by sentence.
+// Code blocks are skipped. Answers are spoken one after another in the order
+// their requests started; a new capture stops whatever is still playing, while
+// late answers and typed follow-ups wait their turn.
+type TTSWriter struct {
+	speaker Speaker
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	answers  []*speech          // answers still to be spoken, oldest first
+	speaking int64              // the request whose sentence is being spoken
+	stop     context.CancelFunc // stops the sentence being spoken
+	closed   bool
+	done     chan struct{}
+}
+
+// speech is the playback state of one request's answer
+type speech struct {
+	id    int64
+	split sentenceSplitter
+	queue []string
+	ended bool // no more sentences will arrive
+}
+
+// NewTTSWriter creates a TTSWriter and starts its playback loop
+func NewTTSWriter(speaker Speaker) *TTSWriter {
+	w := &TTSWriter{
+		speaker: speaker,
+		done:    make(chan struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	return w
+}
+
+// WriteEvent implements StreamWriter
+func (w *TTSWriter) WriteEvent(ev Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+
+	if ev.Type == EventStarted {
+		if !ev.Late && !ev.FollowUp {
+			// A new capture silences the earlier answers
+			w.stopLocked()
+		}
+		w.answers = append(w.answers, &speech{id: ev.RequestID})
+		return nil
+	}
+
+	a := w.find(ev.RequestID)
+	if a == nil {
+		return nil
+	}
+	switch ev.Type {
+	case EventDelta, EventStructured:
+		w.enqueue(a, a.split.Write(ev.Text))
+	case EventCompleted:
+		w.enqueue(a, a.split.Flush())
+		a.ended = true
+		w.cond.Signal()
+	case EventCancelled:
+		// Only the cancelled answer is silenced
+		w.drop(a)
+	case EventError:
+		// Sentences already queued are still spoken
+		a.split = sentenceSplitter{}
+		a.ended = true
+		w.cond.Signal()
+	}
+	return nil
+}
+
+// Stop drops queued sentences and interrupts the one being spoken
+func (w *TTSWriter) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopLocked()
+}
+
+// Close stops playback and ends the playback loop
+func (w *TTSWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.stopLocked()
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+// stopLocked drops every answer and interrupts playback. Caller must hold w.mu.
+func (w *TTSWriter) stopLocked() {
+	w.answers = nil
+	if w.stop != nil {
+		w.stop()
+	}
+}
+
+// find returns the answer for a request, or nil if it isn't being spoken.
+// Caller must hold w.mu.
+func (w *TTSWriter) find(id int64) *speech {
+	for _, a := range w.answers {
+		if a.id == id {
+			return a
+		}
+	}
+	return nil
+}
+
+// drop removes an answer, interrupting it if it is being spoken. Caller must
+// hold w.mu.
+func (w *TTSWriter) drop(a *speech) {
+	for i, other := range w.answers {
+		if other == a {
+			w.answers = append(w.answers[:i], w.answers[i+1:]...)
+			break
+		}
+	}
+	if w.speaking == a.id && w.stop != nil {
+		w.stop()
+	}
+	w.cond.Signal()
+}
+
+// enqueue queues sentences of an answer for the playback loop. Caller must
+// hold w.mu.
+func (w *TTSWriter) enqueue(a *speech, sentences []string) {
+	if len(sentences) == 0 {
+		return
+	}
+	a.queue = append(a.queue, sentences...)
+	w.cond.Signal()
+}
+
+// next returns the next sentence of the oldest answer, dropping answers that
+// have been spoken in full. An answer still streaming holds back the ones
+// after it. Caller must hold w.mu.
+func (w *TTSWriter) next() (int64, string, bool) {
+	for len(w.answers) > 0 {
+		a := w.answers[0]
+		if len(a.queue) > 0 {
+			text := a.queue[0]
+			a.queue = a.queue[1:]
+			return a.id, text, true
+		}
+		if !a.ended {
+			break
+		}
+		w.answers = w.answers[1:]
+	}
+	return 0, "", false
+}
+
+// run speaks queued sentences one at a time until the writer is closed
+func (w *TTSWriter) run() {
+	defer close(w.done)
+
+	for {
+		w.mu.Lock()
+		id, text, ok := w.next()
+		for !ok && !w.closed {
+			w.cond.Wait()
+			id, text, ok = w.next()
+		}
+		if w.closed {
+			w.mu.Unlock()
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		w.speaking = id
+		w.stop = cancel
+		w.mu.Unlock()
+
+		if err := w.speaker.Speak(ctx, text); err != nil {
+			fmt.Printf("[TTSWriter] %s failed: %v\n", w.speaker.Name(), err)
+		}
+
+		w.mu.Lock()
+		w.speaking = 0
+		w.stop = nil
+		w.mu.Unlock()
+		cancel()
+	}
+}
@@ -1,6 +1,7 @@
 package stream
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,11 +16,33 @@ import (
 type WSMessage struct {
 	T     int64  `json:"t"`   // Unix timestamp in milliseconds
 	Seq   int64  `json:"seq"` // Monotonically increasing sequence number
+	// Chunk is the text to display; viewers that ignore events just append it
 	Chunk string `json:"chunk"`
+	Event string `json:"event,omitempty"` // Event type, e.g. "started", "delta" or "completed"
+	// RequestID ties the events of one request together
+	RequestID int64  `json:"requestId,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+	// Text carries the transcript of "transcript" and "partial-transcript" events, the message of "error" events
+	// and the capture time of late "started" events
+	Text string `json:"text,omitempty"`
+	// Data carries the JSON payload of "structured" events
+	Data  json.RawMessage `json:"data,omitempty"`
+	Usage *Usage          `json:"usage,omitempty"`
+	// Late is set on the "started" event of an answer to a queued capture
+	Late bool `json:"late,omitempty"`
+}
+
+// Command is a control message received via WebSocket, e.g.
+// {"type":"command","command":"profile","value":"interview"} or
+// {"type":"command","command":"followup","text":"now do it in O(n)"}
+type Command struct {
+	Name string
+	// Value is the command's optional argument
+	Value string
 }
 
 // CommandHandler is a callback function for handling commands received via WebSocket
-type CommandHandler func(command string)
+type CommandHandler func(cmd Command)
 
 // WSWriter implements StreamWriter for writing to a WebSocket
 type WSWriter struct {
@@ -69,16 +92,57 @@ func (w *WSWriter) SetCommandHandler(handler CommandHandler) {
 
 // WriteChunk writes a chunk to the WebSocket
 func (w *WSWriter) WriteChunk(chunk string) error {
+	return w.send(WSMessage{Chunk: chunk})
+}
+
+// WriteEvent sends an event to the WebSocket. Answer text and markers are
+// also carried as the chunk, so viewers that only read chunks still show them.
+func (w *WSWriter) WriteEvent(ev Event) error {
+	msg := WSMessage{
+		Event:     string(ev.Type),
+		RequestID: ev.RequestID,
+		Profile:   ev.Profile,
+		Data:      ev.Data,
+		Usage:     ev.Usage,
+		Late:      ev.Late,
+	}
+	switch ev.Type {
+	case EventStarted:
+		if ev.Late {
+			msg.Chunk = fmt.Sprintf(lateMarker, ev.Text)
+			msg.Text = ev.Text
+		}
+	case EventDelta, EventStructured:
+		msg.Chunk = ev.Text
+	case EventCached:
+		msg.Chunk = cachedMarker
+	case EventCancelled:
+		msg.Chunk = cancelledMarker
+	case EventError:
+		msg.Chunk = fmt.Sprintf(errorMarker, ev.Text)
+		msg.Text = ev.Text
+	case EventTranscript, EventPartialTranscript:
+		msg.Text = ev.Text
+	}
+
+	err := w.send(msg)
+	if ev.Terminal() {
+		// The request is over either way. Only its own messages are dropped, so
+		// other requests still streaming are replayed on reconnect.
+		w.clearRequest(ev.RequestID)
+	}
+	return err
+}
+
+// send writes a message to the WebSocket, buffering it for replay on reconnect
+func (w *WSWriter) send(msg WSMessage) error {
 	if atomic.LoadInt32(&w.closed) == 1 {
 		return fmt.Errorf("writer is closed")
 	}
 
-	// Create message with timestamp and sequence number
-	msg := WSMessage{
-		T:     time.Now().UnixMilli(),
-		Seq:   atomic.AddInt64(&w.seq, 1),
-		Chunk: chunk,
-	}
+	// Stamp the message with timestamp and sequence number
+	msg.T = time.Now().UnixMilli()
+	msg.Seq = atomic.AddInt64(&w.seq, 1)
 
 	// Try to send immediately if connected
 	w.mu.Lock()
@@ -167,6 +231,26 @@ func (w *WSWriter) ClearBuffer() {
 	// fmt.Printf("[WSWriter] Buffer cleared, removed %d messages\n", bufferSize)
 }
 
+// clearRequest removes the buffered messages of one request. Events without
+// a request ID clear everything, like MarkStreamComplete.
+func (w *WSWriter) clearRequest(id int64) {
+	if id == 0 {
+		w.ClearBuffer()
+		return
+	}
+
+	w.bufferMu.Lock()
+	defer w.bufferMu.Unlock()
+
+	kept := w.buffer[:0]
+	for _, msg := range w.buffer {
+		if msg.RequestID != id {
+			kept = append(kept, msg)
+		}
+	}
+	w.buffer = kept
+}
+
 // ForceReconnection closes the current connection and forces an immediate reconnection
 func (w *WSWriter) ForceReconnection() {
 	fmt.Printf("[WSWriter] Force reconnection requested\n")
@@ -238,6 +322,11 @@ func (w *WSWriter) startReadLoop() {
 			if msgType, ok := msg["type"].(string); ok && msgType == "command" {
 				if command, ok := msg["command"].(string); ok {
 					fmt.Printf("[WSWriter] Received command: %s\n", command)
+					value, _ := msg["value"].(string)
+					if value == "" {
+						// Commands carrying text, such as followup, may send it as "text"
+						value, _ = msg["text"].(string)
+					}
 
 					// Call command handler if set
 					w.mu.Lock()
@@ -246,7 +335,7 @@ func (w *WSWriter) startReadLoop() {
 
 					if handler != nil {
 						// Execute handler in a goroutine to avoid blocking read loop
-						go handler(command)
+						go handler(Command{Name: command, Value: value})
 					}
 				}
 			}
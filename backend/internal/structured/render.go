@@ -0,0 +1,124 @@
+package structured
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Render turns a validated structured answer into markdown for viewers that
+// only display text. A string property named "code" becomes a fenced block,
+// tagged with the "language" property when there is one.
+func Render(schema map[string]any, value any) string {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return inline(value)
+	}
+
+	language, _ := obj["language"].(string)
+	_, hasCode := obj["code"].(string)
+	props, _ := schema["properties"].(map[string]any)
+
+	var out strings.Builder
+	for _, key := range propertyOrder(schema, obj) {
+		if key == "language" && hasCode {
+			continue
+		}
+		title := humanize(key)
+		if sub, ok := props[key].(map[string]any); ok {
+			if t, ok := sub["title"].(string); ok && t != "" {
+				title = t
+			}
+		}
+
+		switch v := obj[key].(type) {
+		case nil:
+			continue
+		case string:
+			if key == "code" {
+				fmt.Fprintf(&out, "**%s**\n\n```%s\n%s\n```\n\n", title, language, strings.TrimRight(v, "\n"))
+			} else {
+				fmt.Fprintf(&out, "**%s**\n\n%s\n\n", title, v)
+			}
+		case []any:
+			if len(v) == 0 {
+				continue
+			}
+			fmt.Fprintf(&out, "**%s**\n\n", title)
+			for _, item := range v {
+				fmt.Fprintf(&out, "- %s\n", inline(item))
+			}
+			out.WriteString("\n")
+		case map[string]any:
+			fmt.Fprintf(&out, "**%s**\n\n", title)
+			for _, sub := range propertyOrder(nil, v) {
+				fmt.Fprintf(&out, "- %s: %s\n", humanize(sub), inline(v[sub]))
+			}
+			out.WriteString("\n")
+		default:
+			fmt.Fprintf(&out, "**%s:** %s\n\n", title, inline(v))
+		}
+	}
+	return strings.TrimSpace(out.String()) + "\n"
+}
+
+// inline renders a value on a single line
+func inline(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	case map[string]any:
+		var fields []string
+		for _, key := range propertyOrder(nil, v) {
+			fields = append(fields, fmt.Sprintf("%s: %s", humanize(key), inline(v[key])))
+		}
+		return strings.Join(fields, "; ")
+	case []any:
+		var items []string
+		for _, item := range v {
+			items = append(items, inline(item))
+		}
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprint(value)
+}
+
+// humanize turns a property name like "timeComplexity" or "time_complexity"
+// into "Time complexity"
+func humanize(key string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, r := range key {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case unicode.IsUpper(r):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	if len(words) == 0 {
+		return key
+	}
+	title := strings.Join(words, " ")
+	runes := []rune(title)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
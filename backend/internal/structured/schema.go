@@ -0,0 +1,174 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Parse decodes a structured answer and validates it against schema.
+// It returns the compacted JSON and the decoded value.
+func Parse(schema map[string]any, content string) (json.RawMessage, any, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, nil, fmt.Errorf("empty answer")
+	}
+
+	var value any
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, nil, fmt.Errorf("answer is not valid JSON: %w", err)
+	}
+	if err := Validate(schema, value); err != nil {
+		return nil, nil, err
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(content)); err != nil {
+		return nil, nil, err
+	}
+	return json.RawMessage(compact.Bytes()), value, nil
+}
+
+// Validate checks value against the subset of JSON schema that structured
+// outputs use: type, properties, required, additionalProperties, items and enum
+func Validate(schema map[string]any, value any) error {
+	return validate(schema, value, "$")
+}
+
+func validate(schema map[string]any, value any, path string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		return fmt.Errorf("%s: expected %v, got %s", path, t, typeName(value))
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				key := fmt.Sprint(name)
+				if _, present := v[key]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, key)
+				}
+			}
+		}
+		for key, item := range v {
+			sub, known := props[key].(map[string]any)
+			if !known {
+				if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+					return fmt.Errorf("%s: unexpected property %q", path, key)
+				}
+				continue
+			}
+			if err := validate(sub, item, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range v {
+			if err := validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether value has the schema type t, which may be a
+// single type name or a list of them
+func matchesType(t any, value any) bool {
+	switch t := t.(type) {
+	case string:
+		return hasType(t, value)
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok && hasType(s, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func hasType(name string, value any) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeName(value) == name
+	}
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// propertyOrder returns the keys of an object in the order the schema lists
+// them under required, followed by any others alphabetically
+func propertyOrder(schema map[string]any, obj map[string]any) []string {
+	seen := map[string]bool{}
+	var keys []string
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			key := fmt.Sprint(name)
+			if _, present := obj[key]; present && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	var rest []string
+	for key := range obj {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
@@ -0,0 +1,407 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// maxReadBytes bounds how much of a file read_file loads into memory
+const maxReadBytes = 1024 * 1024
+
+// capturesDir is where screenshots and recordings are saved
+const capturesDir = ".data"
+
+// NewFromConfig builds a registry with the built-in tools enabled in cfg.
+// It returns nil when tools are disabled or none are configured.
+func NewFromConfig(cfg config.ToolsConfig) (*Registry, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	dirs, err := resolveDirs(cfg.AllowedDirs)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(cfg.TimeoutSec) * time.Second
+
+	reg := NewRegistry(cfg.MaxOutputBytes)
+	if len(dirs) > 0 {
+		reg.Register(&readFileTool{dirs: dirs})
+	}
+	if cfg.Clipboard {
+		reg.Register(&clipboardTool{timeout: timeout})
+	}
+	if cfg.RecentCaptures {
+		reg.Register(&recentCapturesTool{dir: capturesDir})
+	}
+	if len(cfg.AllowedCommands) > 0 {
+		switch {
+		case !cfg.RunCommand:
+			fmt.Println("Warning: tools.allowedCommands is set but run_command is off (set tools.runCommand to enable it)")
+		case len(dirs) == 0:
+			fmt.Println("Warning: run_command needs tools.allowedDirs to run in, leaving it off")
+		default:
+			reg.Register(&runCommandTool{allowed: cfg.AllowedCommands, dirs: dirs, timeout: timeout})
+		}
+	}
+
+	if reg.Len() == 0 {
+		return nil, nil
+	}
+	return reg, nil
+}
+
+// readFileTool reads text files under the allowlisted directories
+type readFileTool struct {
+	dirs []string
+}
+
+func (t *readFileTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Name: "read_file",
+		Description: "Read a text file from the user's machine. Use it to look at the actual source " +
+			"instead of relying on the screenshot. Allowed directories: " + strings.Join(t.dirs, ", "),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{
+					"type":        "string",
+					"description": "Absolute path, or a path relative to the first allowed directory",
+				},
+			},
+			"required": []string{"path"},
+		},
+	}
+}
+
+func (t *readFileTool) Call(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	path, err := allowedPath(in.Path, t.dirs)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxReadBytes))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// clipboardTool reads the macOS clipboard
+type clipboardTool struct {
+	timeout time.Duration
+}
+
+func (t *clipboardTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Name:        "read_clipboard",
+		Description: "Read the current text contents of the user's clipboard.",
+		Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+	}
+}
+
+func (t *clipboardTool) Call(ctx context.Context, args json.RawMessage) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "pbpaste").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %w", err)
+	}
+	if len(out) == 0 {
+		return "(clipboard is empty)", nil
+	}
+	return string(out), nil
+}
+
+// recentCapturesTool lists recent screenshots and recordings
+type recentCapturesTool struct {
+	dir string
+}
+
+func (t *recentCapturesTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Name:        "list_recent_captures",
+		Description: "List the most recent screenshots and audio recordings, newest first.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Maximum number of captures to list (default 10)",
+				},
+			},
+		},
+	}
+}
+
+func (t *recentCapturesTool) Call(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct {
+		Limit int `json:"limit"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if in.Limit <= 0 {
+		in.Limit = 10
+	}
+
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "(no captures yet)", nil
+		}
+		return "", err
+	}
+
+	type capture struct {
+		name string
+		info os.FileInfo
+	}
+	var captures []capture
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".jpg", ".png", ".mp3":
+		default:
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		captures = append(captures, capture{name: entry.Name(), info: info})
+	}
+	if len(captures) == 0 {
+		return "(no captures yet)", nil
+	}
+
+	sort.Slice(captures, func(i, j int) bool {
+		return captures[i].info.ModTime().After(captures[j].info.ModTime())
+	})
+
+	var out strings.Builder
+	for i, c := range captures {
+		if i >= in.Limit {
+			break
+		}
+		fmt.Fprintf(&out, "%s\t%s\t%d bytes\n",
+			filepath.Join(t.dir, c.name), c.info.ModTime().Format(time.RFC3339), c.info.Size())
+	}
+	return out.String(), nil
+}
+
+// runCommandTool runs allowlisted executables without a shell, inside the
+// allowed directories. Arguments naming files elsewhere are rejected.
+type runCommandTool struct {
+	allowed []string
+	dirs    []string
+	timeout time.Duration
+}
+
+func (t *runCommandTool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Name: "run_command",
+		Description: "Run a command on the user's machine and return its output. " +
+			"Only these executables are allowed: " + strings.Join(t.allowed, ", ") + ". " +
+			"It runs in, and may only touch files under: " + strings.Join(t.dirs, ", "),
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command": map[string]any{"type": "string", "description": "Executable name"},
+				"args": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Arguments, passed as is without a shell",
+				},
+				"dir": map[string]any{
+					"type":        "string",
+					"description": "Working directory; must be inside an allowed directory (defaults to the first one)",
+				},
+			},
+			"required": []string{"command"},
+		},
+	}
+}
+
+func (t *runCommandTool) Call(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct {
+		Command string   `json:"command"`
+		Args    []string `json:"args"`
+		Dir     string   `json:"dir"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	allowed := false
+	for _, name := range t.allowed {
+		if in.Command == name {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("command %q is not allowed", in.Command)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	dir := t.dirs[0]
+	if in.Dir != "" {
+		var err error
+		if dir, err = allowedPath(in.Dir, t.dirs); err != nil {
+			return "", err
+		}
+	}
+	for _, arg := range in.Args {
+		if err := checkArg(arg, dir, t.dirs); err != nil {
+			return "", err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, in.Command, in.Args...)
+	cmd.Dir = dir
+
+	fmt.Printf("🛠️  run_command: %s %s\n", in.Command, strings.Join(in.Args, " "))
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(out), fmt.Errorf("command timed out after %v", t.timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Sprintf("%s\n[exit code %d]", out, exitErr.ExitCode()), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// checkArg rejects a command argument that names a file outside dirs.
+// Relative paths are taken from dir, the command's working directory. Words
+// that aren't files and don't look like paths, such as subcommands, pass.
+func checkArg(arg, dir string, dirs []string) error {
+	value := arg
+	if strings.HasPrefix(arg, "-") {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			// A path glued to a flag, e.g. -C/etc, can't be told apart reliably
+			if strings.ContainsRune(arg, filepath.Separator) || strings.Contains(arg, "~") {
+				return fmt.Errorf("argument %q: pass paths as separate arguments or after =", arg)
+			}
+			return nil
+		}
+		value = arg[i+1:]
+	}
+	if value == "" {
+		return nil
+	}
+
+	pathLike := value == "." || value == ".." || strings.HasPrefix(value, "~") ||
+		strings.ContainsRune(value, filepath.Separator)
+	path := expandHome(value)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	// Files that don't exist yet, e.g. an output file, are checked by the
+	// nearest parent that does, so a symlinked parent can't escape
+	existing := path
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	if existing != path && !pathLike {
+		return nil
+	}
+	if _, err := allowedPath(existing, dirs); err != nil {
+		return fmt.Errorf("argument %q: %w", arg, err)
+	}
+	return nil
+}
+
+// resolveDirs expands and canonicalizes the allowlisted directories
+func resolveDirs(dirs []string) ([]string, error) {
+	var resolved []string
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(expandHome(dir))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed dir %q: %w", dir, err)
+		}
+		canonical, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed dir %q: %w", dir, err)
+		}
+		resolved = append(resolved, canonical)
+	}
+	return resolved, nil
+}
+
+// allowedPath resolves path (following symlinks) and checks it lies inside one of dirs
+func allowedPath(path string, dirs []string) (string, error) {
+	if path == "" {
+		return "", errors.New("path is required")
+	}
+	if len(dirs) == 0 {
+		return "", errors.New("no allowed directories configured")
+	}
+
+	path = expandHome(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dirs[0], path)
+	}
+	canonical, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(dir, canonical)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return canonical, nil
+		}
+	}
+	return "", fmt.Errorf("%s is outside the allowed directories", path)
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
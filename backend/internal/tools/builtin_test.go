@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// allowedTree creates an allowed directory holding notes.txt and a symlink
+// to a secret file outside it, and returns both directories
+func allowedTree(t *testing.T) (allowed, outside string) {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed = filepath.Join(root, "allowed")
+	outside = filepath.Join(root, "outside")
+	for _, dir := range []string{allowed, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(allowed, "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(allowed, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	return allowed, outside
+}
+
+func TestCheckArg(t *testing.T) {
+	allowed, outside := allowedTree(t)
+	dirs := []string{allowed}
+
+	tests := []struct {
+		arg string
+		ok  bool
+	}{
+		{"notes.txt", true},
+		{"./notes.txt", true},
+		{filepath.Join(allowed, "notes.txt"), true},
+		{"new/output.txt", true},
+		{"log", true},
+		{"--oneline", true},
+		{"--format=%H", true},
+		{"HEAD~3", true},
+		{"-n", true},
+		{".", true},
+		{"..", false},
+		{"../outside/secret.txt", false},
+		{filepath.Join(outside, "secret.txt"), false},
+		{"/etc/passwd", false},
+		{"~/.ssh/id_rsa", false},
+		{"link.txt", false},
+		{"--output=" + filepath.Join(outside, "x"), false},
+		{"-C" + outside, false},
+		{"../missing/file", false},
+	}
+	for _, tt := range tests {
+		err := checkArg(tt.arg, allowed, dirs)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkArg(%q) = %v, want ok=%v", tt.arg, err, tt.ok)
+		}
+	}
+}
+
+func TestRunCommandStaysInAllowedDirs(t *testing.T) {
+	allowed, outside := allowedTree(t)
+	tool := &runCommandTool{allowed: []string{"cat", "pwd"}, dirs: []string{allowed}, timeout: 5 * time.Second}
+
+	call := func(command string, args ...string) (string, error) {
+		raw, _ := json.Marshal(map[string]any{"command": command, "args": args})
+		return tool.Call(context.Background(), raw)
+	}
+
+	out, err := call("pwd")
+	if err != nil || strings.TrimSpace(out) != allowed {
+		t.Errorf("pwd = %q, %v; want the first allowed dir %q", out, err, allowed)
+	}
+	if out, err := call("cat", "notes.txt"); err != nil || out != "notes" {
+		t.Errorf("cat notes.txt = %q, %v", out, err)
+	}
+	if out, err := call("cat", filepath.Join(outside, "secret.txt")); err == nil {
+		t.Errorf("cat outside the allowed dirs succeeded: %q", out)
+	}
+	if out, err := call("rm", "notes.txt"); err == nil {
+		t.Errorf("a command that isn't allowlisted ran: %q", out)
+	}
+}
+
+func TestRunCommandNeedsOptIn(t *testing.T) {
+	allowed, _ := allowedTree(t)
+	cfg := config.ToolsConfig{Enabled: true, AllowedDirs: []string{allowed}, AllowedCommands: []string{"ls"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	has := func(cfg config.ToolsConfig) bool {
+		reg, err := NewFromConfig(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reg == nil {
+			return false
+		}
+		for _, def := range reg.Definitions() {
+			if def.Name == "run_command" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if has(cfg) {
+		t.Error("run_command was enabled without runCommand")
+	}
+	cfg.RunCommand = true
+	if !has(cfg) {
+		t.Error("run_command was not enabled with runCommand")
+	}
+	cfg.AllowedDirs = nil
+	if has(cfg) {
+		t.Error("run_command was enabled without allowedDirs")
+	}
+}
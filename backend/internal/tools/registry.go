@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// Tool is a local capability the model can call during a turn
+type Tool interface {
+	// Definition describes the tool and its JSON schema to the model
+	Definition() llm.ToolDefinition
+	// Call runs the tool with the model-provided JSON arguments
+	Call(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// Registry holds the tools offered to the model
+type Registry struct {
+	mu             sync.RWMutex
+	tools          map[string]Tool
+	order          []string
+	maxOutputBytes int
+}
+
+// NewRegistry creates an empty registry. Tool results longer than
+// maxOutputBytes are truncated (0 means no limit).
+func NewRegistry(maxOutputBytes int) *Registry {
+	return &Registry{
+		tools:          map[string]Tool{},
+		maxOutputBytes: maxOutputBytes,
+	}
+}
+
+// Register adds a tool, replacing any tool with the same name
+func (r *Registry) Register(tool Tool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := tool.Definition().Name
+	if _, exists := r.tools[name]; !exists {
+		r.order = append(r.order, name)
+	}
+	r.tools[name] = tool
+}
+
+// Len returns the number of registered tools
+func (r *Registry) Len() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.tools)
+}
+
+// Definitions returns the tool definitions in registration order
+func (r *Registry) Definitions() []llm.ToolDefinition {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]llm.ToolDefinition, 0, len(r.order))
+	for _, name := range r.order {
+		defs = append(defs, r.tools[name].Definition())
+	}
+	return defs
+}
+
+// Call runs the requested tool. Failures are returned as text so the model
+// can see what went wrong and recover, rather than aborting the turn.
+func (r *Registry) Call(ctx context.Context, call llm.ToolCall) string {
+	r.mu.RLock()
+	tool, ok := r.tools[call.Name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return fmt.Sprintf("error: unknown tool %q", call.Name)
+	}
+
+	args := json.RawMessage(call.Arguments)
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	out, err := tool.Call(ctx, args)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	return r.truncate(out)
+}
+
+func (r *Registry) truncate(out string) string {
+	if r.maxOutputBytes <= 0 || len(out) <= r.maxOutputBytes {
+		return out
+	}
+	return out[:r.maxOutputBytes] + fmt.Sprintf("\n[truncated, %d bytes omitted]", len(out)-r.maxOutputBytes)
+}
@@ -0,0 +1,60 @@
+package transcribe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// Transcriber turns a recorded question into text
+type Transcriber interface {
+	// Name identifies the backend in logs
+	Name() string
+	// Transcribe returns the speech in the audio file at audioPath
+	Transcribe(ctx context.Context, audioPath string) (*llm.Transcription, error)
+}
+
+// New returns the backend selected in cfg. The openai and openai-compatible
+// backends send audio through provider; whisper.cpp runs locally and ignores it.
+func New(cfg config.TranscriptionConfig, provider llm.Provider) (Transcriber, error) {
+	switch cfg.Backend {
+	case config.TranscriptionOpenAI, config.TranscriptionOpenAICompatible:
+		return FromProvider(cfg, provider), nil
+	case config.TranscriptionWhisperCpp:
+		return NewWhisperCpp(cfg)
+	default:
+		return nil, fmt.Errorf("unknown transcription backend %q", cfg.Backend)
+	}
+}
+
+// providerTranscriber transcribes through an LLM provider's audio API
+type providerTranscriber struct {
+	name     string
+	provider llm.Provider
+	opts     llm.TranscriptionOptions
+}
+
+// FromProvider transcribes with provider using the model, language, prompt
+// and temperature from cfg
+func FromProvider(cfg config.TranscriptionConfig, provider llm.Provider) Transcriber {
+	return &providerTranscriber{
+		name:     cfg.Backend,
+		provider: provider,
+		opts: llm.TranscriptionOptions{
+			Model:       cfg.Model,
+			Language:    cfg.Language,
+			Prompt:      cfg.Prompt,
+			Temperature: cfg.Temperature,
+		},
+	}
+}
+
+// Name implements Transcriber
+func (t *providerTranscriber) Name() string { return t.name }
+
+// Transcribe implements Transcriber
+func (t *providerTranscriber) Transcribe(ctx context.Context, audioPath string) (*llm.Transcription, error) {
+	return t.provider.Transcribe(ctx, audioPath, t.opts)
+}
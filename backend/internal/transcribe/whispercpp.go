@@ -0,0 +1,133 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// WhisperCpp runs a local whisper.cpp executable, so speech never leaves the machine
+type WhisperCpp struct {
+	command     string
+	model       string
+	language    string
+	prompt      string
+	temperature *float64
+	timeout     time.Duration
+}
+
+// NewWhisperCpp checks that the whisper.cpp executable, the model file and
+// ffmpeg are available
+func NewWhisperCpp(cfg config.TranscriptionConfig) (*WhisperCpp, error) {
+	command, err := exec.LookPath(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp not found (install it with `brew install whisper-cpp`): %w", err)
+	}
+	if _, err := os.Stat(cfg.Model); err != nil {
+		return nil, fmt.Errorf("whisper.cpp model not found: %w", err)
+	}
+	// whisper.cpp reads 16 kHz WAV, recordings are converted with ffmpeg
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	return &WhisperCpp{
+		command:     command,
+		model:       cfg.Model,
+		language:    cfg.Language,
+		prompt:      cfg.Prompt,
+		temperature: cfg.Temperature,
+		timeout:     time.Duration(cfg.TimeoutSec) * time.Second,
+	}, nil
+}
+
+// Name implements Transcriber
+func (w *WhisperCpp) Name() string { return config.TranscriptionWhisperCpp }
+
+// Transcribe implements Transcriber
+func (w *WhisperCpp) Transcribe(ctx context.Context, audioPath string) (*llm.Transcription, error) {
+	if audioPath == "" {
+		return &llm.Transcription{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	wavPath, err := w.convert(ctx, audioPath)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(wavPath)
+
+	language := w.language
+	if language == "" {
+		language = "auto"
+	}
+	// -nt drops timestamps so stdout is just the text
+	args := []string{"-m", w.model, "-f", wavPath, "-l", language, "-nt", "-np"}
+	if w.prompt != "" {
+		args = append(args, "--prompt", w.prompt)
+	}
+	if w.temperature != nil {
+		args = append(args, "-tp", strconv.FormatFloat(*w.temperature, 'f', -1, 64))
+	}
+
+	cmd := exec.CommandContext(ctx, w.command, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("whisper.cpp timed out after %v", w.timeout)
+		}
+		return nil, fmt.Errorf("whisper.cpp failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	duration, err := llm.AudioDuration(wavPath)
+	if err != nil {
+		fmt.Printf("Warning: failed to measure %s: %v\n", audioPath, err)
+	}
+	return &llm.Transcription{
+		Text:     joinLines(string(out)),
+		Model:    filepath.Base(w.model),
+		Duration: duration,
+	}, nil
+}
+
+// convert writes audioPath as the 16 kHz mono WAV whisper.cpp expects
+func (w *WhisperCpp) convert(ctx context.Context, audioPath string) (string, error) {
+	file, err := os.CreateTemp("", "whisper-*.wav")
+	if err != nil {
+		return "", err
+	}
+	file.Close()
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-i", audioPath, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", file.Name())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to convert %s for whisper.cpp: %w: %s", audioPath, err, strings.TrimSpace(stderr.String()))
+	}
+	return file.Name(), nil
+}
+
+// joinLines joins the segments whisper.cpp prints one per line
+func joinLines(out string) string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,93 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// espeakWordsPerMinute is espeak's default speaking rate
+const espeakWordsPerMinute = 175
+
+// Espeak speaks through the espeak-ng command line tool, entirely offline
+type Espeak struct {
+	command string
+	voice   string
+	speed   float64
+}
+
+// NewEspeak checks that the espeak executable is available
+func NewEspeak(cfg config.TTSConfig) (*Espeak, error) {
+	command, err := exec.LookPath(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("espeak not found (install it with `brew install espeak-ng`): %w", err)
+	}
+	return &Espeak{command: command, voice: cfg.Voice, speed: cfg.Speed}, nil
+}
+
+// Name implements Backend
+func (e *Espeak) Name() string { return config.TTSEspeak }
+
+// Speak implements Backend
+func (e *Espeak) Speak(ctx context.Context, text string) error {
+	args := []string{"-s", strconv.Itoa(int(espeakWordsPerMinute * e.speed))}
+	if e.voice != "" {
+		args = append(args, "-v", e.voice)
+	}
+	// "--" keeps text starting with "-" from being read as a flag
+	args = append(args, "--", text)
+	return run(ctx, exec.CommandContext(ctx, e.command, args...))
+}
+
+// Piper synthesizes speech with a local piper voice and plays it
+type Piper struct {
+	command string
+	model   string
+	speed   float64
+	player  string
+}
+
+// NewPiper checks that piper, its voice model and the audio player are available
+func NewPiper(cfg config.TTSConfig) (*Piper, error) {
+	command, err := exec.LookPath(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("piper not found: %w", err)
+	}
+	if _, err := os.Stat(cfg.Model); err != nil {
+		return nil, fmt.Errorf("piper voice not found: %w", err)
+	}
+	player, err := exec.LookPath(cfg.Player)
+	if err != nil {
+		return nil, fmt.Errorf("audio player %q not found: %w", cfg.Player, err)
+	}
+	return &Piper{command: command, model: cfg.Model, speed: cfg.Speed, player: player}, nil
+}
+
+// Name implements Backend
+func (p *Piper) Name() string { return config.TTSPiper }
+
+// Speak implements Backend
+func (p *Piper) Speak(ctx context.Context, text string) error {
+	file, err := os.CreateTemp("", "speech-*.wav")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	// piper reads the text from stdin; a longer length scale is slower speech
+	cmd := exec.CommandContext(ctx, p.command,
+		"--model", p.model,
+		"--output_file", file.Name(),
+		"--length_scale", strconv.FormatFloat(1/p.speed, 'f', 2, 64))
+	cmd.Stdin = strings.NewReader(text)
+	if err := run(ctx, cmd); err != nil || ctx.Err() != nil {
+		return err
+	}
+	return play(ctx, p.player, file.Name())
+}
@@ -0,0 +1,44 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// File appends each sentence to a text file instead of speaking it, which
+// shows exactly what would be spoken without any audio setup
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFile creates a file sink writing to cfg.Path
+func NewFile(cfg config.TTSConfig) *File {
+	return &File{path: cfg.Path}
+}
+
+// Name implements Backend
+func (f *File) Name() string { return config.TTSFile }
+
+// Speak implements Backend
+func (f *File) Speak(ctx context.Context, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), FOLDER_PERMS_READALL_WRITEOWN); err != nil {
+		return fmt.Errorf("failed to create TTS dir: %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintln(file, text)
+	return err
+}
@@ -0,0 +1,88 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	openai "github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// OpenAI synthesizes speech with the OpenAI speech API and plays it locally
+type OpenAI struct {
+	client openai.Client
+	model  string
+	voice  string
+	speed  float64
+	player string
+}
+
+// NewOpenAI creates the backend from the provider's endpoint settings
+func NewOpenAI(cfg config.TTSConfig, endpoint llm.Config) (*OpenAI, error) {
+	if endpoint.BaseURL == "" && endpoint.APIKey == "" {
+		return nil, errors.New("OPENAI_API_KEY not set")
+	}
+	player, err := exec.LookPath(cfg.Player)
+	if err != nil {
+		return nil, fmt.Errorf("audio player %q not found: %w", cfg.Player, err)
+	}
+
+	opts := []option.RequestOption{}
+	if endpoint.BaseURL != "" {
+		opts = append(opts, option.WithBaseURL(endpoint.BaseURL))
+	}
+	if endpoint.APIKey != "" {
+		opts = append(opts, option.WithAPIKey(endpoint.APIKey))
+	}
+	return &OpenAI{
+		client: openai.NewClient(opts...),
+		model:  cfg.Model,
+		voice:  cfg.Voice,
+		speed:  cfg.Speed,
+		player: player,
+	}, nil
+}
+
+// Name implements Backend
+func (o *OpenAI) Name() string { return config.TTSOpenAI }
+
+// Speak implements Backend
+func (o *OpenAI) Speak(ctx context.Context, text string) error {
+	resp, err := o.client.Audio.Speech.New(ctx, openai.AudioSpeechNewParams{
+		Input:          text,
+		Model:          o.model,
+		Voice:          openai.AudioSpeechNewParamsVoice(o.voice),
+		Speed:          openai.Float(o.speed),
+		ResponseFormat: openai.AudioSpeechNewParamsResponseFormatMP3,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("speech request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	file, err := os.CreateTemp("", "speech-*.mp3")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, resp.Body)
+	file.Close()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to download speech: %w", err)
+	}
+	return play(ctx, o.player, file.Name())
+}
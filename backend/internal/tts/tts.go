@@ -0,0 +1,60 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+const FOLDER_PERMS_READALL_WRITEOWN = 0755
+
+// Backend says text aloud
+type Backend interface {
+	// Name identifies the backend in logs
+	Name() string
+	// Speak says text and returns when it has been spoken. Cancelling ctx
+	// stops playback.
+	Speak(ctx context.Context, text string) error
+}
+
+// New returns the backend selected in cfg. endpoint holds the API
+// connection used by the openai backend.
+func New(cfg config.TTSConfig, endpoint llm.Config) (Backend, error) {
+	switch cfg.Backend {
+	case config.TTSOpenAI:
+		return NewOpenAI(cfg, endpoint)
+	case config.TTSEspeak:
+		return NewEspeak(cfg)
+	case config.TTSPiper:
+		return NewPiper(cfg)
+	case config.TTSFile:
+		return NewFile(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown TTS backend %q", cfg.Backend)
+	}
+}
+
+// play plays an audio file with the configured player
+func play(ctx context.Context, player, path string) error {
+	return run(ctx, exec.CommandContext(ctx, player, path))
+}
+
+// run runs cmd, adding its stderr to any error. Being killed because ctx was
+// cancelled is not an error, it means playback was stopped.
+func run(ctx context.Context, cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("%s failed: %w: %s", filepath.Base(cmd.Path), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/llm"
+)
+
+// meteredProvider decorates a Provider and records the usage of every request
+type meteredProvider struct {
+	llm.Provider
+	store   *Store
+	profile func() string
+}
+
+// Meter wraps p so that every chat and transcription request is recorded in
+// store. Requests are tagged with the profile set by WithProfile, or else the
+// one profile returns at the time of the request.
+func Meter(p llm.Provider, store *Store, profile func() string) llm.Provider {
+	return &meteredProvider{Provider: p, store: store, profile: profile}
+}
+
+type profileKey struct{}
+
+// WithProfile records requests made with ctx under profile, e.g. the one a
+// request was sent with rather than the one active when it finishes
+func WithProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, profileKey{}, profile)
+}
+
+// StreamChat implements llm.Provider
+func (m *meteredProvider) StreamChat(ctx context.Context, req llm.ChatRequest, onDelta llm.DeltaFunc) (*llm.ChatResponse, error) {
+	resp, err := m.Provider.StreamChat(ctx, req, onDelta)
+	if err != nil {
+		return resp, err
+	}
+
+	var imageTokens int64
+	for _, msg := range req.Messages {
+		for _, part := range msg.Parts {
+			if part.Type == llm.PartImage {
+				imageTokens += int64(llm.EstimateImageTokens(part.ImageURL, req.ImageDetail))
+			}
+		}
+	}
+
+	record := Record{
+		Kind:             KindChat,
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		CachedTokens:     resp.Usage.CachedTokens,
+		ImageTokens:      imageTokens,
+	}
+	// Some compatible servers don't report usage; estimate it instead
+	if record.PromptTokens == 0 && record.CompletionTokens == 0 {
+		record.PromptTokens = int64(llm.EstimateTokens(req.Messages, req.ImageDetail))
+		record.CompletionTokens = int64(llm.EstimateTextTokens(resp.Content))
+		record.Estimated = true
+	}
+	m.record(ctx, record)
+	return resp, nil
+}
+
+// Transcribe implements llm.Provider
+func (m *meteredProvider) Transcribe(ctx context.Context, audioPath string, opts llm.TranscriptionOptions) (*llm.Transcription, error) {
+	result, err := m.Provider.Transcribe(ctx, audioPath, opts)
+	if err != nil || audioPath == "" {
+		return result, err
+	}
+
+	m.record(ctx, Record{
+		Kind:         KindTranscription,
+		Model:        result.Model,
+		AudioSeconds: result.Duration.Seconds(),
+	})
+	return result, nil
+}
+
+func (m *meteredProvider) record(ctx context.Context, record Record) {
+	record.Time = time.Now()
+	record.Provider = m.Provider.Name()
+	if profile, ok := ctx.Value(profileKey{}).(string); ok {
+		record.Profile = profile
+	} else if m.profile != nil {
+		record.Profile = m.profile()
+	}
+	if err := m.store.Append(record); err != nil {
+		fmt.Printf("Warning: failed to record usage: %v\n", err)
+	}
+}
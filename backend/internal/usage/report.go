@@ -0,0 +1,166 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PeterShin23/MyAssistant/backend/internal/config"
+)
+
+// DefaultPrices are list prices in USD at the time of writing; override or
+// extend them with "prices" in rules.json
+var DefaultPrices = map[string]config.ModelPrice{
+	"gpt-4.1":                {InputPerMTok: 2.00, CachedInputPerMTok: 0.50, OutputPerMTok: 8.00},
+	"gpt-4.1-mini":           {InputPerMTok: 0.40, CachedInputPerMTok: 0.10, OutputPerMTok: 1.60},
+	"gpt-4.1-nano":           {InputPerMTok: 0.10, CachedInputPerMTok: 0.025, OutputPerMTok: 0.40},
+	"gpt-4o":                 {InputPerMTok: 2.50, CachedInputPerMTok: 1.25, OutputPerMTok: 10.00},
+	"gpt-4o-mini":            {InputPerMTok: 0.15, CachedInputPerMTok: 0.075, OutputPerMTok: 0.60},
+	"whisper-1":              {PerAudioMinute: 0.006},
+	"gpt-4o-transcribe":      {PerAudioMinute: 0.006},
+	"gpt-4o-mini-transcribe": {PerAudioMinute: 0.003},
+}
+
+// Totals accumulates usage over a group of records
+type Totals struct {
+	Requests         int
+	PromptTokens     int64
+	CompletionTokens int64
+	ImageTokens      int64
+	AudioSeconds     float64
+	// Cost is the estimated spend of the priced records
+	Cost float64
+	// Unpriced counts records whose model has no price
+	Unpriced int
+}
+
+func (t *Totals) add(r Record, cost float64, priced bool) {
+	t.Requests++
+	t.PromptTokens += r.PromptTokens
+	t.CompletionTokens += r.CompletionTokens
+	t.ImageTokens += r.ImageTokens
+	t.AudioSeconds += r.AudioSeconds
+	t.Cost += cost
+	if !priced {
+		t.Unpriced++
+	}
+}
+
+// Row is the usage of one model, on one day when grouped by day
+type Row struct {
+	Day   string
+	Model string
+	Totals
+}
+
+// Report groups usage per day and model and per model
+type Report struct {
+	ByDay   []Row
+	ByModel []Row
+	Total   Totals
+}
+
+// Summarize groups records and prices them with prices, falling back to DefaultPrices
+func Summarize(records []Record, prices map[string]config.ModelPrice) Report {
+	byDay := map[[2]string]*Row{}
+	byModel := map[string]*Row{}
+	var report Report
+
+	for _, r := range records {
+		day := r.Time.Local().Format("2006-01-02")
+		cost, priced := Cost(r, prices)
+
+		key := [2]string{day, r.Model}
+		if byDay[key] == nil {
+			byDay[key] = &Row{Day: day, Model: r.Model}
+		}
+		byDay[key].add(r, cost, priced)
+
+		if byModel[r.Model] == nil {
+			byModel[r.Model] = &Row{Model: r.Model}
+		}
+		byModel[r.Model].add(r, cost, priced)
+
+		report.Total.add(r, cost, priced)
+	}
+
+	for _, row := range byDay {
+		report.ByDay = append(report.ByDay, *row)
+	}
+	sort.Slice(report.ByDay, func(i, j int) bool {
+		if report.ByDay[i].Day != report.ByDay[j].Day {
+			return report.ByDay[i].Day < report.ByDay[j].Day
+		}
+		return report.ByDay[i].Model < report.ByDay[j].Model
+	})
+
+	for _, row := range byModel {
+		report.ByModel = append(report.ByModel, *row)
+	}
+	sort.Slice(report.ByModel, func(i, j int) bool {
+		return report.ByModel[i].Cost > report.ByModel[j].Cost
+	})
+	return report
+}
+
+// Cost estimates the spend of a record. It reports false when the model has no price.
+func Cost(r Record, prices map[string]config.ModelPrice) (float64, bool) {
+	price, ok := priceFor(r.Model, prices)
+	if !ok {
+		return 0, false
+	}
+
+	if r.Kind == KindTranscription {
+		return r.AudioSeconds / 60 * price.PerAudioMinute, true
+	}
+
+	cached := r.CachedTokens
+	uncached := r.PromptTokens - cached
+	cachedPrice := price.CachedInputPerMTok
+	if cachedPrice == 0 {
+		cachedPrice = price.InputPerMTok
+	}
+	return (float64(uncached)*price.InputPerMTok +
+		float64(cached)*cachedPrice +
+		float64(r.CompletionTokens)*price.OutputPerMTok) / 1e6, true
+}
+
+// priceFor looks a model up in prices, then DefaultPrices. Dated snapshots
+// such as "gpt-4.1-2025-04-14" match the longest priced prefix.
+func priceFor(model string, prices map[string]config.ModelPrice) (config.ModelPrice, bool) {
+	for _, table := range []map[string]config.ModelPrice{prices, DefaultPrices} {
+		if price, ok := table[model]; ok {
+			return price, true
+		}
+	}
+
+	best := ""
+	var bestPrice config.ModelPrice
+	for _, table := range []map[string]config.ModelPrice{prices, DefaultPrices} {
+		for name, price := range table {
+			if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+				best, bestPrice = name, price
+			}
+		}
+	}
+	return bestPrice, best != ""
+}
+
+// ParseSince parses a lookback such as "7d", "36h" or "90m"
+func ParseSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 7d, 36h or 90m)", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,106 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const FOLDER_PERMS_READALL_WRITEOWN = 0755
+
+// Path is the usage log, one JSON record per provider request
+var Path = filepath.Join(".data", "usage", "usage.jsonl")
+
+// Request kinds
+const (
+	KindChat          = "chat"
+	KindTranscription = "transcription"
+)
+
+// Record is the usage of a single provider request
+type Record struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Profile  string    `json:"profile,omitempty"`
+	// PromptTokens includes ImageTokens and CachedTokens
+	PromptTokens     int64 `json:"promptTokens,omitempty"`
+	CompletionTokens int64 `json:"completionTokens,omitempty"`
+	CachedTokens     int64 `json:"cachedTokens,omitempty"`
+	// ImageTokens is estimated locally; providers don't report it separately
+	ImageTokens  int64   `json:"imageTokens,omitempty"`
+	AudioSeconds float64 `json:"audioSeconds,omitempty"`
+	// Estimated is set when the provider reported no token counts
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// Store appends usage records to Path
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a store writing to Path
+func NewStore() *Store {
+	return &Store{path: Path}
+}
+
+// Append adds a record to the log
+func (s *Store) Append(record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), FOLDER_PERMS_READALL_WRITEOWN); err != nil {
+		return fmt.Errorf("failed to create usage dir: %w", err)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Read returns the records at or after since, oldest first.
+// A missing log yields no records.
+func Read(since time.Time) ([]Record, error) {
+	file, err := os.Open(Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// Skip a line torn by a crash rather than losing the whole log
+			continue
+		}
+		if !record.Time.Before(since) {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
@@ -21,6 +21,7 @@ export default function App() {
   const [wsUrl, setWsUrl] = useState(`ws://${address}:4000/stream?role=viewer`);
   const [isConnected, setIsConnected] = useState(false);
   const [content, setContent] = useState("");
+  const [followUp, setFollowUp] = useState("");
   const [isLandscape, setIsLandscape] = useState(screen.width > screen.height);
 
   const scrollRef = useRef(null);
@@ -31,6 +32,7 @@ export default function App() {
   const pendingRef = useRef("");
   const rafRef = useRef(null);
   const flushScheduledRef = useRef(false); // guard to avoid rescheduling within same frame
+  const hasContentRef = useRef(false); // whether a request boundary needs a separator
 
   Dimensions.addEventListener("change", ({ screen }) => {
     if (isLandscape !== screen.width > screen.height) {
@@ -105,6 +107,13 @@ export default function App() {
         const parsed = JSON.parse(data);
 
         chunk = typeof parsed?.chunk === "string" ? parsed.chunk : data; // fall back to raw
+
+        // Typed events: separate requests and show what was heard
+        if (parsed?.event === "started" && hasContentRef.current) {
+          chunk = "\n\n---\n\n" + chunk;
+        } else if (parsed?.event === "transcript" && parsed.text) {
+          chunk = `> 🎤 ${parsed.text}\n\n`;
+        }
         // console.log('[Frontend] Extracted chunk:', chunk);
       } catch (error) {
         // console.log('[Frontend] JSON parse failed, using raw data:', error.message);
@@ -116,6 +125,8 @@ export default function App() {
       // console.log('[Frontend] Final chunk to append:', chunk);
       // console.log('[Frontend] Pending content before append:', pendingRef.current.length, 'chars');
 
+      if (chunk.length === 0) return;
+      hasContentRef.current = true;
       pendingRef.current += chunk;
       // console.log('[Frontend] Pending content after append:', pendingRef.current.length, 'chars');
 
@@ -151,6 +162,7 @@ export default function App() {
   const clearContent = () => {
     setContent("");
     pendingRef.current = "";
+    hasContentRef.current = false;
     if (rafRef.current) {
       cancelAnimationFrame(rafRef.current);
       rafRef.current = null;
@@ -169,6 +181,42 @@ export default function App() {
     }
   };
 
+  const triggerCancel = () => {
+    if (wsRef.current && isConnected) {
+      const commandMessage = JSON.stringify({
+        type: "command",
+        command: "cancel",
+      });
+      wsRef.current.send(commandMessage);
+      console.log("[Frontend] Sent cancel command");
+    }
+  };
+
+  const triggerCycleProfile = () => {
+    if (wsRef.current && isConnected) {
+      const commandMessage = JSON.stringify({
+        type: "command",
+        command: "cycle-profile",
+      });
+      wsRef.current.send(commandMessage);
+      console.log("[Frontend] Sent cycle-profile command");
+    }
+  };
+
+  const sendFollowUp = () => {
+    const text = followUp.trim();
+    if (wsRef.current && isConnected && text) {
+      const commandMessage = JSON.stringify({
+        type: "command",
+        command: "followup",
+        text,
+      });
+      wsRef.current.send(commandMessage);
+      console.log("[Frontend] Sent followup command");
+      setFollowUp("");
+    }
+  };
+
   const onScroll = (e) => {
     const { layoutMeasurement, contentOffset, contentSize } = e.nativeEvent;
     const atBottom =
@@ -200,6 +248,22 @@ export default function App() {
             >
               <Icon name="photo-camera" size={24} color="#fff" />
             </TouchableOpacity>
+            <TouchableOpacity
+              style={styles.cancelButton}
+              onPress={triggerCancel}
+              accessible={true}
+              accessibilityLabel="Cancel answer"
+            >
+              <Icon name="stop" size={24} color="#fff" />
+            </TouchableOpacity>
+            <TouchableOpacity
+              style={styles.profileButton}
+              onPress={triggerCycleProfile}
+              accessible={true}
+              accessibilityLabel="Next profile"
+            >
+              <Icon name="swap-horiz" size={24} color="#fff" />
+            </TouchableOpacity>
             <TouchableOpacity
               style={styles.clearButton}
               onPress={clearContent}
@@ -217,6 +281,27 @@ export default function App() {
         )}
       </View>
 
+      {isConnected && (
+        <View style={styles.inputRow}>
+          <TextInput
+            style={styles.input}
+            value={followUp}
+            onChangeText={setFollowUp}
+            placeholder="Ask a follow-up…"
+            returnKeyType="send"
+            onSubmitEditing={sendFollowUp}
+          />
+          <TouchableOpacity
+            style={styles.screenshotButton}
+            onPress={sendFollowUp}
+            accessible={true}
+            accessibilityLabel="Send follow-up"
+          >
+            <Icon name="send" size={24} color="#fff" />
+          </TouchableOpacity>
+        </View>
+      )}
+
       <ScrollView
         ref={scrollRef}
         style={styles.scroll}
@@ -259,6 +344,26 @@ const styles = StyleSheet.create({
     borderWidth: 1,
     borderColor: "#4CAF50",
   },
+  cancelButton: {
+    width: 44,
+    height: 44,
+    borderRadius: 8,
+    backgroundColor: "#f0a030",
+    justifyContent: "center",
+    alignItems: "center",
+    borderWidth: 1,
+    borderColor: "#f0a030",
+  },
+  profileButton: {
+    width: 44,
+    height: 44,
+    borderRadius: 8,
+    backgroundColor: "#3f7fd0",
+    justifyContent: "center",
+    alignItems: "center",
+    borderWidth: 1,
+    borderColor: "#3f7fd0",
+  },
   clearButton: {
     width: 44,
     height: 44,